@@ -1,14 +1,29 @@
 package main
 
 type FirstLineInput struct {
-	BaseCost         int
-	NumberOfPackages int
+	BaseCost         Money `json:"baseCost"`
+	NumberOfPackages int   `json:"numberOfPackages"`
 }
 
 type PackageDetail struct {
-	Index    int
-	Title    string
-	Weight   int
-	Distance int
-	OfferIds []string
+	Index    int      `json:"-"`
+	Title    string   `json:"title"`
+	Weight   float64  `json:"weight"`   // At most 3 decimals
+	Distance float64  `json:"distance"` // At most 3 decimals
+	OfferIds []string `json:"offerIds"`
+	// Optional size of the package, used for the volumetric weight
+	Dimensions *Dimensions `json:"dimensions,omitempty"`
+	// Optional delivery window, hours since the dispatch start like 4.5 or a RFC 3339 timestamp
+	NotBefore string `json:"notBefore,omitempty"`
+	DueBy     string `json:"dueBy,omitempty"`
+	// Optional priority level, empty is standard
+	Priority Priority `json:"priority,omitempty"`
+	// Optional destination, needed by the routing mode
+	Location *Point `json:"location,omitempty"`
+}
+
+type Dimensions struct {
+	Length int `json:"length"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
 }
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// An input error together with the line number it was found on
+type InputLineError struct {
+	Line int
+	Err  error
+}
+
+func (e *InputLineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e *InputLineError) Unwrap() error {
+	return e.Err
+}
+
+// Function to solve a problem without prompts, only the outputs are written to stdout
+// The packages are read from the manifest file if a path is given, the input then has no package lines
+// It returns the process exit status
+func runBatch(problems []Problem, problemKey string, inputPath string, manifestPath string, options SolverOptions, renderer Renderer, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	problem, err := findProblem(problems, problemKey)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	input := stdin
+	if inputPath != "-" {
+		file, err := os.Open(inputPath)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		defer file.Close()
+		input = file
+	}
+
+	var manifest []PackageDetail
+	if manifestPath != "" {
+		if manifest, err = LoadManifest(manifestPath); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+
+	checker := newPackageChecker(&options.Offers)
+	firstLineInput, packageDetails, extraDetails, err := readBatchInputs(input, problem, manifest, checker)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	for _, warning := range checker.Warnings {
+		fmt.Fprintln(stderr, warning)
+	}
+
+	result, err := problem.Solver(firstLineInput, packageDetails, extraDetails, options)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if err := renderer(stdout, result); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// Function to find a problem by its key
+func findProblem(problems []Problem, problemKey string) (Problem, error) {
+	for _, problem := range problems {
+		if problem.Key == problemKey {
+			return problem, nil
+		}
+	}
+	return Problem{}, fmt.Errorf("read problem error: '%s' is not a known problem number", problemKey)
+}
+
+// Function to read a whole problem in the same format as the interactive console
+// Blank lines are ignored, every issue of the package and shipment lines is reported together in a ValidationError
+// The first line and a missing or extra line stop the reading, as the lines after them can't be told apart
+// With the packages of a manifest, the first line may only have the base cost and there are no package lines
+func readBatchInputs(input io.Reader, problem Problem, manifest []PackageDetail, checker *packageChecker) (FirstLineInput, []PackageDetail, [][]string, error) {
+	scanner := bufio.NewScanner(input)
+	lineNumber := 0
+	issues := []InputIssue{}
+
+	nextLine := func(expected string) (inputLine, error) {
+		for scanner.Scan() {
+			lineNumber++
+			line := strings.TrimRight(scanner.Text(), "\r\n")
+			if strings.TrimSpace(line) != "" {
+				return newInputLine(lineNumber, line), nil
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return inputLine{}, err
+		}
+		return inputLine{}, &InputLineError{Line: lineNumber + 1, Err: fmt.Errorf("unexpected end of input, expected %s", expected)}
+	}
+	// The error which stops the reading comes after the issues found before it
+	stop := func(err error) error {
+		var lineErr *InputLineError
+		if validationError(issues) == nil || !errors.As(err, &lineErr) {
+			return err
+		}
+		return validationError(append(issues, InputIssue{Line: lineErr.Line, Message: lineErr.Err.Error()}))
+	}
+
+	line, err := nextLine(problem.Schema.FirstLine.Name)
+	if err != nil {
+		return FirstLineInput{}, nil, nil, err
+	}
+	if manifest != nil && len(line.Tokens) == 1 {
+		// The number of packages is the one of the manifest
+		line.Tokens = append(line.Tokens, strconv.Itoa(len(manifest)))
+	}
+	firstLineInput, issues := validateFirstLine(line)
+	if manifest != nil && len(issues) == 0 && firstLineInput.NumberOfPackages != len(manifest) {
+		issues = append(issues, line.issue(1, "numberOfPackages",
+			fmt.Sprintf("parse first input line error: The manifest has %d packages, not %d", len(manifest), firstLineInput.NumberOfPackages)))
+	}
+	if err := validationError(issues); err != nil {
+		return FirstLineInput{}, nil, nil, err
+	}
+
+	packageDetails := []PackageDetail{}
+	if manifest != nil {
+		packageDetails = manifest
+		for _, packageDetail := range manifest {
+			checker.check(packageDetail, InputIssue{}, 0)
+		}
+	}
+	for len(packageDetails) < firstLineInput.NumberOfPackages {
+		line, err := nextLine(fmt.Sprintf("%s %d of %d", problem.Schema.Package.Name, len(packageDetails)+1, firstLineInput.NumberOfPackages))
+		if err != nil {
+			return FirstLineInput{}, nil, nil, stop(err)
+		}
+		packageDetail, lineIssues := checker.checkLine(line, len(packageDetails))
+		issues = append(issues, lineIssues...)
+		packageDetails = append(packageDetails, packageDetail)
+	}
+
+	extraDetails := [][]string{}
+	for _, extraLine := range problem.Schema.ExtraLines {
+		line, err := nextLine(extraLine.Name)
+		if err != nil {
+			return FirstLineInput{}, nil, nil, stop(err)
+		}
+		extraDetails = append(extraDetails, line.Tokens)
+	}
+	if problem.ValidateExtraLines != nil && len(extraDetails) > 0 {
+		if err := problem.ValidateExtraLines(extraDetails); err != nil {
+			issues = append(issues, InputIssue{Line: lineNumber, Message: err.Error()})
+		}
+	}
+
+	if _, err := nextLine(""); err == nil {
+		return FirstLineInput{}, nil, nil, stop(&InputLineError{Line: lineNumber, Err: fmt.Errorf("unexpected extra input")})
+	}
+	if err := validationError(issues); err != nil {
+		return FirstLineInput{}, nil, nil, err
+	}
+
+	return firstLineInput, packageDetails, extraDetails, nil
+}
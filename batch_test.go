@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadBatchInputs(t *testing.T) {
+	problems := listProblems()
+
+	t.Run("return the inputs of a valid file", func(t *testing.T) {
+		input := "100 2\n\nPKG1 5 5 OFR001\nPKG2 15 5 OFR002\n2 70 200\n"
+		firstLineInput, packageDetails, extraDetails, err := readBatchInputs(strings.NewReader(input), problems[1], nil, newPackageChecker(nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, FirstLineInput{BaseCost: moneyOf(100), NumberOfPackages: 2}, firstLineInput)
+		assert.Equal(t, 2, len(packageDetails))
+		assert.Equal(t, 1, packageDetails[1].Index)
+		assert.Equal(t, [][]string{{"2", "70", "200"}}, extraDetails)
+	})
+	t.Run("return the line of an invalid package", func(t *testing.T) {
+		input := "100 2\nPKG1 5 5 OFR001\nPKG2 15s 5 OFR002\n"
+		_, _, _, err := readBatchInputs(strings.NewReader(input), problems[0], nil, newPackageChecker(nil))
+
+		assert.EqualError(t, err, "line 3, column 6: parse package inputs error: Wrong package weight input")
+	})
+	t.Run("return the issues of every package line together", func(t *testing.T) {
+		input := "100 3\nPKG1 5 5 OFR001\nPKG2 -15 5s OFR002\nPKG1 10 5 OFR003\n"
+		_, _, _, err := readBatchInputs(strings.NewReader(input), problems[0], nil, newPackageChecker(nil))
+
+		assert.EqualError(t, err, "line 3, column 6: Validate package error: The weight of package PKG2 should be more than 0, not -15\n"+
+			"line 3, column 10: parse package inputs error: Wrong package distance input\n"+
+			"line 4, column 1: Validate package error: Duplicate package id PKG1, first used on line 2")
+	})
+	t.Run("return the issues found before a missing line", func(t *testing.T) {
+		input := "100 2\nPKG1 5s 5 OFR001\n"
+		_, _, _, err := readBatchInputs(strings.NewReader(input), problems[0], nil, newPackageChecker(nil))
+
+		assert.EqualError(t, err, "line 2, column 6: parse package inputs error: Wrong package weight input\n"+
+			"line 3: unexpected end of input, expected package 2 of 2")
+	})
+	t.Run("return the line of an invalid shipment detail", func(t *testing.T) {
+		input := "100 1\nPKG1 5 5 OFR001\n2 70\n"
+		_, _, _, err := readBatchInputs(strings.NewReader(input), problems[1], nil, newPackageChecker(nil))
+
+		assert.EqualError(t, err, "line 3: Validate extra details error: Wrong number of inputs")
+	})
+	t.Run("take the packages of a manifest and infer their number", func(t *testing.T) {
+		manifest := []PackageDetail{{Title: "PKG1", Weight: 5, Distance: 5, OfferIds: []string{}}, {Title: "PKG2", Index: 1, Weight: 15, Distance: 5, OfferIds: []string{}}}
+		firstLineInput, packageDetails, _, err := readBatchInputs(strings.NewReader("100\n"), problems[0], manifest, newPackageChecker(nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, FirstLineInput{BaseCost: moneyOf(100), NumberOfPackages: 2}, firstLineInput)
+		assert.Equal(t, manifest, packageDetails)
+	})
+	t.Run("return error for a number of packages other than the manifest", func(t *testing.T) {
+		manifest := []PackageDetail{{Title: "PKG1", Weight: 5, Distance: 5, OfferIds: []string{}}}
+		_, _, _, err := readBatchInputs(strings.NewReader("100 3\n"), problems[0], manifest, newPackageChecker(nil))
+
+		assert.EqualError(t, err, "line 1, column 5: parse first input line error: The manifest has 1 packages, not 3")
+	})
+	t.Run("return error for missing packages", func(t *testing.T) {
+		input := "100 3\nPKG1 5 5 OFR001\n"
+		_, _, _, err := readBatchInputs(strings.NewReader(input), problems[0], nil, newPackageChecker(nil))
+
+		assert.EqualError(t, err, "line 3: unexpected end of input, expected package 2 of 3")
+	})
+	t.Run("return error for extra lines", func(t *testing.T) {
+		input := "100 1\nPKG1 5 5 OFR001\nPKG2 5 5 OFR001\n"
+		_, _, _, err := readBatchInputs(strings.NewReader(input), problems[0], nil, newPackageChecker(nil))
+
+		assert.EqualError(t, err, "line 3: unexpected extra input")
+	})
+}
+
+func TestRunBatch(t *testing.T) {
+	problems := listProblems()
+	options := SolverOptions{Offers: defaultOfferCatalog()}
+	renderer, _ := getRenderer("text", RenderOptions{})
+
+	t.Run("write only the outputs to stdout", func(t *testing.T) {
+		stdin := strings.NewReader("100 3\nPKG1 5 5 OFR001\nPKG2 15 5 OFR002\nPKG3 10 100 OFR003\n")
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		status := runBatch(problems, "1", "-", "", options, renderer, stdin, stdout, stderr)
+
+		assert.Equal(t, 0, status)
+		assert.Equal(t, "PKG1 0 175\nPKG2 0 275\nPKG3 35 665\n", stdout.String())
+		assert.Equal(t, "", stderr.String())
+	})
+	t.Run("write the warnings to stderr", func(t *testing.T) {
+		stdin := strings.NewReader("100 1\nPKG1 5 5 OFR008\n")
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		status := runBatch(problems, "1", "-", "", options, renderer, stdin, stdout, stderr)
+
+		assert.Equal(t, 0, status)
+		assert.Equal(t, "PKG1 0 175\n", stdout.String())
+		assert.Equal(t, "line 2, column 10: warning: Unknown offer code OFR008 of package PKG1, it gives no discount\n", stderr.String())
+	})
+	t.Run("exit with non-zero status on parse error", func(t *testing.T) {
+		stdin := strings.NewReader("100 x\n")
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		status := runBatch(problems, "1", "-", "", options, renderer, stdin, stdout, stderr)
+
+		assert.Equal(t, 1, status)
+		assert.Equal(t, "", stdout.String())
+		assert.Equal(t, "line 1, column 5: parse first input line error: Wrong number of packages input\n", stderr.String())
+	})
+	t.Run("exit with non-zero status for unknown problem", func(t *testing.T) {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		status := runBatch(problems, "9", "-", "", options, renderer, strings.NewReader(""), stdout, stderr)
+
+		assert.Equal(t, 2, status)
+		assert.Contains(t, stderr.String(), "'9' is not a known problem number")
+	})
+}
@@ -0,0 +1,519 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The version of the app, set when building with -ldflags "-X main.version=1.2.0"
+var version = "dev"
+
+// A subcommand of the command line, e.g. "lets_help_kiki cost --input problem.txt"
+type command struct {
+	Name    string
+	Summary string
+	Run     func(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int
+}
+
+// Function to return the subcommands, every problem with an API route is a subcommand with the name of its route
+func listCommands() []command {
+	commands := []command{}
+	for _, problem := range listProblems() {
+		if problem.Route == "" {
+			continue
+		}
+		problem := problem
+		commands = append(commands, command{
+			Name:    problem.Route,
+			Summary: "solve the " + problem.Title + " problem",
+			Run: func(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+				return runProblemCommand(problem, problem.Route, false, args, stdin, stdout, stderr)
+			},
+		})
+		// Only the delivery times have trips, so the plan is the time problem with its dispatch plan
+		if problem.Route == "time" {
+			commands = append(commands, command{
+				Name:    "plan",
+				Summary: "plan the trips of the " + problem.Title + " problem, with the dispatch plan",
+				Run: func(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+					return runProblemCommand(problem, "plan", true, args, stdin, stdout, stderr)
+				},
+			})
+		}
+	}
+	return append(commands,
+		command{Name: "offers", Summary: "list the offers of a catalog, or validate catalog files", Run: runOffers},
+		command{Name: "serve", Summary: "run the HTTP API", Run: func(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+			return runServe(args, stderr)
+		}},
+		command{Name: "version", Summary: "write the version of the app", Run: runVersion},
+		command{Name: "help", Summary: "write the help of the app or of a command", Run: runHelp},
+	)
+}
+
+// Function to find a subcommand by its name
+func findCommand(commands []command, name string) (command, bool) {
+	for _, command := range commands {
+		if command.Name == name {
+			return command, true
+		}
+	}
+	return command{}, false
+}
+
+// Function to write the usage of the app, with its subcommands and the inputs of the problems
+func writeUsage(output io.Writer, commands []command, problems []Problem) {
+	fmt.Fprintln(output, "Usage:")
+	fmt.Fprintln(output, "  lets_help_kiki [flags]             answer the prompts of a problem, or solve it with --problem")
+	fmt.Fprintln(output, "  lets_help_kiki COMMAND [flags]")
+	fmt.Fprintln(output, "")
+	fmt.Fprintln(output, "Commands:")
+	for _, command := range commands {
+		fmt.Fprintf(output, "  %-8s %s\n", command.Name, command.Summary)
+	}
+	fmt.Fprintln(output, "")
+	fmt.Fprintln(output, `Run "lets_help_kiki COMMAND --help" for the flags of a command.`)
+	fmt.Fprintln(output, "")
+	writeProblemsHelp(output, problems)
+}
+
+// The flags of the solver options and the output, shared by the flags without a command and the problem commands
+type solverFlags struct {
+	offersPath          *string
+	pricingPath         *string
+	pricingModel        *string
+	packingBudget       *time.Duration
+	outputFormat        *string
+	skipUnshippable     *bool
+	orderDate           *string
+	customerSegments    *string
+	rounding            *string
+	timePrecision       *int
+	timeRounding        *string
+	roundAtPresentation *bool
+	timeUnit            *string
+	dispatchStart       *string
+	shifts              *string
+	workingDays         *string
+	objective           *string
+	optimize            *bool
+	optimizeSeed        *int64
+	optimizeTime        *string
+	optimizeIterations  *int
+	routing             *bool
+	prioritizeDeadlines *bool
+	dispatchPlan        *bool
+}
+
+// Function to define the flags of the solver options and the output on a flag set
+func addSolverFlags(flags *flag.FlagSet) *solverFlags {
+	return &solverFlags{
+		offersPath:          flags.String("offers", "", "path of the offer catalog file (.json, .yaml or .yml)"),
+		pricingPath:         flags.String("pricing", "", "path of the pricing models file (.json, .yaml or .yml)"),
+		pricingModel:        flags.String("pricing-model", "", "name of the pricing model to use (default the default model of the pricing file)"),
+		packingBudget:       flags.Duration("packing-budget", 0, "time limit of the exact shipment packing, e.g. 500ms (0 means no limit)"),
+		outputFormat:        flags.String("output", "text", "output format: "+strings.Join(rendererNames(), ", ")),
+		skipUnshippable:     flags.Bool("skip-unshippable", false, "mark packages no vehicle can carry as UNDELIVERABLE instead of failing"),
+		orderDate:           flags.String("order-date", "", "day of the order for the offer validity dates, YYYY-MM-DD (default today)"),
+		customerSegments:    flags.String("segments", "", "comma separated segment tags of the customer, e.g. business,vip"),
+		rounding:            flags.String("rounding", "", "rounding of the amounts to cents: half-up, half-even or floor (default the one of the pricing model)"),
+		timePrecision:       flags.Int("time-precision", 2, "number of decimals of the delivery times"),
+		timeRounding:        flags.String("time-rounding", "", "rounding of the delivery times: truncate (default), round or ceil"),
+		roundAtPresentation: flags.Bool("round-at-presentation", false, "plan the trips with exact times and round only the output times"),
+		timeUnit:            flags.String("time-unit", "", "unit of the delivery times: hours (default), minutes or clock"),
+		dispatchStart:       flags.String("dispatch-start", "", "wall-clock time of the first dispatch for the clock time unit, e.g. 2024-03-15T08:00:00Z"),
+		shifts:              flags.String("shifts", "", "comma separated daily shifts of the vehicles without own shifts, e.g. 08:00-12:00,13:00-18:00"),
+		workingDays:         flags.String("working-days", "", "comma separated days with shifts, e.g. mon,tue,wed,thu,fri (default every day)"),
+		objective:           flags.String("objective", "", "packing objective of the shipments: "+strings.Join(packingObjectiveNames(), ", ")+" (default "+defaultPackingObjective+")"),
+		optimize:            flags.Bool("optimize", false, "improve the greedy plan of the trips with simulated annealing, for an earlier last delivery"),
+		optimizeSeed:        flags.Int64("optimize-seed", 0, "seed of the optimizer, the same seed gives the same plan unless the time limit stops it"),
+		optimizeTime:        flags.String("optimize-time", "0", "time limit of the optimizer, e.g. 500ms (0 means no limit, a run stopped by it isn't reproducible)"),
+		optimizeIterations:  flags.Int("optimize-iterations", defaultOptimizerIterations, "number of moves the optimizer tries"),
+		routing:             flags.Bool("routing", false, "time the trips along a route through the package locations (at=X,Y) instead of direct legs"),
+		prioritizeDeadlines: flags.Bool("prioritize-deadlines", false, "ship the packages which would miss their due time on a later trip first"),
+		dispatchPlan:        flags.Bool("dispatch-plan", false, "add the vehicle and trip of each package and the dispatch plan to the output"),
+	}
+}
+
+// Function to build the solver options and the renderer from the flags, the errors are written to stderr
+// The status is 1 for a file which can't be loaded, 2 for a wrong flag value and 0 if both are built
+func (f *solverFlags) build(stderr io.Writer) (SolverOptions, Renderer, int) {
+	options, err := loadSolverOptions(*f.offersPath, *f.pricingPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return options, nil, 1
+	}
+	options.PackingTimeBudget = *f.packingBudget
+	options.SkipUnshippable = *f.skipUnshippable
+	options.PrioritizeDeadlines = *f.prioritizeDeadlines
+	options.Routing = *f.routing
+	setters := []func() error{
+		func() error { return setPricingModel(&options, *f.pricingModel) },
+		func() error { return setRounding(&options, *f.rounding) },
+		func() error {
+			return setOrderOptions(&options, *f.orderDate, splitCommaList(*f.customerSegments))
+		},
+		func() error { return setPackingObjective(&options, *f.objective) },
+		func() error {
+			return setOptimizer(&options, *f.optimize, *f.optimizeSeed, *f.optimizeTime, *f.optimizeIterations)
+		},
+		func() error {
+			return setTimeModel(&options, *f.timePrecision, *f.timeRounding, *f.roundAtPresentation, *f.timeUnit, *f.dispatchStart)
+		},
+		func() error {
+			return setSchedule(&options, splitCommaList(*f.shifts), splitCommaList(*f.workingDays))
+		},
+	}
+	for _, set := range setters {
+		if err := set(); err != nil {
+			fmt.Fprintln(stderr, err)
+			return options, nil, 2
+		}
+	}
+
+	renderer, err := getRenderer(*f.outputFormat, RenderOptions{DispatchPlan: *f.dispatchPlan, TimeModel: options.TimeModel})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return options, nil, 2
+	}
+	return options, renderer, 0
+}
+
+// Function to return the logger of the --verbose flag, it writes nothing without the flag
+func verboseLogger(stderr io.Writer, verbose bool) *log.Logger {
+	if !verbose {
+		return log.New(io.Discard, "", 0)
+	}
+	return log.New(stderr, "", 0)
+}
+
+// Function to describe where a setting comes from, for the verbose logs
+func sourceOf(path string) string {
+	if path == "" {
+		return "default"
+	}
+	return path
+}
+
+// Function to parse the flags of a command and then apply its config file, withArgs accepts arguments after the flags
+// The status is 0 to go on, -1 if only the help was asked or the exit status of wrong flags
+func parseCommandFlags(flags *flag.FlagSet, configPath *string, withArgs bool, args []string, stderr io.Writer) int {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return -1
+		}
+		return 2
+	}
+	if flags.NArg() > 0 && !withArgs {
+		fmt.Fprintf(stderr, "command error: Unexpected argument '%s' of %s\n", flags.Arg(0), flags.Name())
+		return 2
+	}
+	if err := applyConfig(flags, *configPath); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	return 0
+}
+
+// Function to solve a problem with the inputs of a file or stdin, dispatchPlan adds the dispatch plan by default
+func runProblemCommand(problem Problem, name string, dispatchPlan bool, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	solver := addSolverFlags(flags)
+	inputPath := flags.String("input", "-", "input file, use - for stdin")
+	manifestPath := flags.String("manifest", "", "CSV (.csv) or JSON Lines (.jsonl) file with the packages, the input then has no package lines")
+	configPath := flags.String("config", "", "YAML or JSON file with the value of the flags by their name, the flags of the command line come first")
+	verbose := flags.Bool("verbose", false, "write the loaded files, the settings and the solve time to stderr")
+	if dispatchPlan {
+		*solver.dispatchPlan = true
+		flags.Lookup("dispatch-plan").DefValue = "true"
+	}
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: lets_help_kiki %s [flags]\n\n", name)
+		writeProblemsHelp(stderr, []Problem{problem})
+		fmt.Fprintln(stderr, "\nFlags:")
+		flags.PrintDefaults()
+	}
+	if status := parseCommandFlags(flags, configPath, false, args, stderr); status != 0 {
+		return maxStatus(status)
+	}
+
+	options, renderer, status := solver.build(stderr)
+	if status != 0 {
+		return status
+	}
+	logger := verboseLogger(stderr, *verbose)
+	logger.Printf("problem: %s", problem.Title)
+	if *configPath != "" {
+		logger.Printf("config: %s", *configPath)
+	}
+	logger.Printf("offers: %d offers, %s catalog", len(options.Offers.Offers), sourceOf(*solver.offersPath))
+	logger.Printf("pricing: %s model, %s catalog", options.PricingModel.Name, sourceOf(*solver.pricingPath))
+	logger.Printf("input: %s", *inputPath)
+	if *manifestPath != "" {
+		logger.Printf("manifest: %s", *manifestPath)
+	}
+	logger.Printf("output: %s", *solver.outputFormat)
+
+	start := time.Now()
+	status = runBatch([]Problem{problem}, problem.Key, *inputPath, *manifestPath, options, renderer, stdin, stdout, stderr)
+	logger.Printf("solved in %s with status %d", time.Since(start).Round(time.Millisecond), status)
+	return status
+}
+
+// Function to turn the status of parseCommandFlags into an exit status, the help only is a success
+func maxStatus(status int) int {
+	if status < 0 {
+		return 0
+	}
+	return status
+}
+
+// Function to run "offers list" or "offers validate"
+func runOffers(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	usage := "Usage: lets_help_kiki offers list [flags] | lets_help_kiki offers validate [flags] [PATH...]"
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, usage)
+		return 2
+	}
+	switch args[0] {
+	case "list":
+		return runOffersList(args[1:], stdout, stderr)
+	case "validate":
+		return runOffersValidate(args[1:], stdout, stderr)
+	case "-h", "-help", "--help":
+		fmt.Fprintln(stderr, usage)
+		return 0
+	}
+	fmt.Fprintf(stderr, "offers error: Unknown offers command '%s', use list or validate\n", args[0])
+	return 2
+}
+
+// Function to write the offers of a catalog, the default catalog if no file is given
+func runOffersList(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("offers list", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	offersPath := flags.String("offers", "", "path of the offer catalog file (.json, .yaml or .yml), the default offers without it")
+	outputFormat := flags.String("output", "text", "output format: text or json")
+	configPath := flags.String("config", "", "YAML or JSON file with the value of the flags by their name, the flags of the command line come first")
+	verbose := flags.Bool("verbose", false, "write the loaded catalog to stderr")
+	if status := parseCommandFlags(flags, configPath, false, args, stderr); status != 0 {
+		return maxStatus(status)
+	}
+	if *outputFormat != "text" && *outputFormat != "json" {
+		fmt.Fprintf(stderr, "output format error: '%s' is not a known format, use one of json, text\n", *outputFormat)
+		return 2
+	}
+
+	options, err := loadSolverOptions(*offersPath, "")
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	verboseLogger(stderr, *verbose).Printf("offers: %d offers, %s catalog", len(options.Offers.Offers), sourceOf(*offersPath))
+	if *outputFormat == "json" {
+		return writeJSONOutput(stdout, stderr, options.Offers)
+	}
+	writeOffersTable(stdout, options.Offers)
+	return 0
+}
+
+// Function to write a value as indented JSON, like the json output format
+func writeJSONOutput(stdout io.Writer, stderr io.Writer, value interface{}) int {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// Function to write the offers as a table with their discount and ranges, and the stacking policy
+func writeOffersTable(output io.Writer, catalog OfferCatalog) {
+	writer := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tTYPE\tDISCOUNT\tDISTANCE\tWEIGHT\tRULE")
+	for _, offer := range catalog.Offers {
+		rule := "-"
+		if offer.Rule != nil {
+			rule = "yes"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", offer.Id, offer.offerType(), offerDiscount(offer), offerRange(offer.Distance), offerRange(offer.Weight), rule)
+	}
+	writer.Flush()
+
+	policy := catalog.Stacking.Policy
+	if policy == "" {
+		policy = StackingSum
+	}
+	fmt.Fprintf(output, "Stacking: %s", policy)
+	if catalog.Stacking.MaxPercent > 0 {
+		fmt.Fprintf(output, ", max %d%%", catalog.Stacking.MaxPercent)
+	}
+	if catalog.Stacking.MaxAmount > 0 {
+		fmt.Fprintf(output, ", max %s", catalog.Stacking.MaxAmount)
+	}
+	fmt.Fprintln(output)
+}
+
+// Function to describe the discount of an offer by its type, e.g. "10%" or "5% up to 10, 8%"
+func offerDiscount(offer Offer) string {
+	switch offer.offerType() {
+	case OfferFlat:
+		return fmt.Sprintf("%s off", offer.Amount)
+	case OfferFreeDistance:
+		return fmt.Sprintf("first %s free", formatMeasure(offer.FreeDistance))
+	case OfferPerKg:
+		return fmt.Sprintf("%s per kg", offer.Amount)
+	case OfferTiered:
+		tiers := make([]string, len(offer.Tiers))
+		for i, tier := range offer.Tiers {
+			tiers[i] = fmt.Sprintf("%d%%", tier.Percent)
+			if tier.UpToWeight > 0 {
+				tiers[i] += fmt.Sprintf(" up to %d", tier.UpToWeight)
+			}
+		}
+		return strings.Join(tiers, ", ")
+	}
+	return fmt.Sprintf("%d%%", offer.Percent)
+}
+
+// Function to describe the range of an offer, a zero upper bound means no limit
+func offerRange(amount CompareAmount) string {
+	if amount.LessThanEqual == 0 {
+		return fmt.Sprintf("%d+", amount.GreaterThanEqual)
+	}
+	return fmt.Sprintf("%d-%d", amount.GreaterThanEqual, amount.LessThanEqual)
+}
+
+// Function to check offer catalog files, the paths are the arguments or the --offers flag
+func runOffersValidate(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("offers validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	offersPath := flags.String("offers", "", "path of the offer catalog file (.json, .yaml or .yml) to check with the arguments")
+	configPath := flags.String("config", "", "YAML or JSON file with the value of the flags by their name, the flags of the command line come first")
+	verbose := flags.Bool("verbose", false, "write every checked file to stderr")
+	if status := parseCommandFlags(flags, configPath, true, args, stderr); status != 0 {
+		return maxStatus(status)
+	}
+	paths := flags.Args()
+	if *offersPath != "" {
+		paths = append([]string{*offersPath}, paths...)
+	}
+	if len(paths) == 0 {
+		fmt.Fprintln(stderr, "offers error: No offer catalog to validate, give its path")
+		return 2
+	}
+
+	logger := verboseLogger(stderr, *verbose)
+	status := 0
+	for _, path := range paths {
+		logger.Printf("checking %s", path)
+		catalog, err := LoadOfferCatalog(path)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			status = 1
+			continue
+		}
+		fmt.Fprintf(stdout, "%s: %d offers are valid\n", path, len(catalog.Offers))
+	}
+	return status
+}
+
+// Function to write the version of the app and of Go
+func runVersion(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("version", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	outputFormat := flags.String("output", "text", "output format: text or json")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *outputFormat == "json" {
+		return writeJSONOutput(stdout, stderr, map[string]string{"version": version, "go": runtime.Version()})
+	}
+	fmt.Fprintf(stdout, "lets_help_kiki %s (%s)\n", version, runtime.Version())
+	return 0
+}
+
+// Function to write the help of the app, or the help of the command given as argument
+func runHelp(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	commands := listCommands()
+	if len(args) == 0 {
+		writeUsage(stdout, commands, listProblems())
+		return 0
+	}
+	command, ok := findCommand(commands, args[0])
+	if !ok || command.Name == "help" {
+		fmt.Fprintf(stderr, "command error: Unknown command '%s'\n", args[0])
+		return 2
+	}
+	return command.Run([]string{"--help"}, stdin, stdout, stdout)
+}
+
+// Function to set the flags which aren't given on the command line from a YAML or JSON config file
+// The keys of the file are the flag names and the lists can be written as arrays, e.g. "segments: [business, vip]"
+func applyConfig(flags *flag.FlagSet, path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config error: %v", err)
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("config error: %s: %v", path, err)
+	}
+
+	given := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name == "config" || flags.Lookup(name) == nil {
+			return fmt.Errorf("config error: Unknown option '%s' in %s", name, path)
+		}
+		if given[name] {
+			continue
+		}
+		if err := flags.Set(name, configValue(values[name])); err != nil {
+			return fmt.Errorf("config error: Wrong value of '%s' in %s: %v", name, path, err)
+		}
+	}
+	return nil
+}
+
+// Function to write a value of the config file like the value of its flag, the lists are comma separated
+func configValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value)
+}
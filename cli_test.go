@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Function to run a command by its name with the given stdin, it returns the status, stdout and stderr
+func runCommand(t *testing.T, name string, args []string, stdin string) (int, string, string) {
+	command, ok := findCommand(listCommands(), name)
+	assert.True(t, ok)
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	status := command.Run(args, strings.NewReader(stdin), stdout, stderr)
+	return status, stdout.String(), stderr.String()
+}
+
+func TestListCommands(t *testing.T) {
+	t.Run("make a command of every problem route and the other commands", func(t *testing.T) {
+		names := []string{}
+		for _, command := range listCommands() {
+			names = append(names, command.Name)
+		}
+
+		assert.Equal(t, []string{"cost", "time", "plan", "offers", "serve", "version", "help"}, names)
+	})
+	t.Run("write the commands and the problems in the help", func(t *testing.T) {
+		status, stdout, _ := runCommand(t, "help", nil, "")
+
+		assert.Equal(t, 0, status)
+		assert.Contains(t, stdout, "  plan     plan the trips of the Delivery Time Estimation problem, with the dispatch plan\n")
+		assert.Contains(t, stdout, "  1. Delivery Cost Estimation with Offers (--problem 1, POST /v1/estimate/cost)\n")
+	})
+	t.Run("write the inputs and the flags of a problem command", func(t *testing.T) {
+		status, stdout, _ := runCommand(t, "help", []string{"time"}, "")
+
+		assert.Equal(t, 0, status)
+		assert.Contains(t, stdout, "Usage: lets_help_kiki time [flags]\n")
+		assert.Contains(t, stdout, "shipment detail, 1 line:")
+		assert.Contains(t, stdout, "  -config string\n")
+		assert.NotContains(t, stdout, "Delivery Cost Estimation")
+	})
+	t.Run("return error for an unknown command", func(t *testing.T) {
+		status, _, stderr := runCommand(t, "help", []string{"ship"}, "")
+
+		assert.Equal(t, 2, status)
+		assert.Equal(t, "command error: Unknown command 'ship'\n", stderr)
+	})
+}
+
+func TestRunProblemCommand(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("solve the problem of the command with the inputs of stdin", func(t *testing.T) {
+		status, stdout, stderr := runCommand(t, "cost", nil, "100 1\nPKG1 5 5 OFR001\n")
+
+		assert.Equal(t, 0, status)
+		assert.Equal(t, "PKG1 0 175\n", stdout)
+		assert.Equal(t, "", stderr)
+	})
+	t.Run("add the dispatch plan in the plan command", func(t *testing.T) {
+		status, stdout, _ := runCommand(t, "plan", nil, "100 1\nPKG1 5 5 OFR001\n1 70 200\n")
+
+		assert.Equal(t, 0, status)
+		assert.Equal(t, "PKG1 0 175 0.07 1 1\n<----------- Dispatch plan ----------->\n1 1 0.00 0.14 PKG1\n", stdout)
+	})
+	t.Run("take the flags of the config file which aren't given", func(t *testing.T) {
+		path := filepath.Join(dir, "config.yaml")
+		assert.NoError(t, os.WriteFile(path, []byte("output: json\ndispatch-plan: true\n"), 0o644))
+		status, stdout, _ := runCommand(t, "cost", []string{"--config", path, "--output", "csv"}, "100 1\nPKG1 5 5 OFR001\n")
+
+		assert.Equal(t, 0, status)
+		assert.True(t, strings.HasPrefix(stdout, "title,discount,total_cost"), stdout)
+	})
+	t.Run("write the settings and the solve time with verbose", func(t *testing.T) {
+		status, _, stderr := runCommand(t, "cost", []string{"--verbose"}, "100 1\nPKG1 5 5 OFR001\n")
+
+		assert.Equal(t, 0, status)
+		assert.Contains(t, stderr, "offers: 3 offers, default catalog\n")
+		assert.Contains(t, stderr, "solved in ")
+	})
+	t.Run("exit with status 2 for a wrong flag value", func(t *testing.T) {
+		status, stdout, stderr := runCommand(t, "time", []string{"--output", "xml"}, "")
+
+		assert.Equal(t, 2, status)
+		assert.Equal(t, "", stdout)
+		assert.Contains(t, stderr, "'xml' is not a known format")
+	})
+	t.Run("exit with status 2 for an argument after the flags", func(t *testing.T) {
+		status, _, stderr := runCommand(t, "cost", []string{"problem.txt"}, "")
+
+		assert.Equal(t, 2, status)
+		assert.Equal(t, "command error: Unexpected argument 'problem.txt' of cost\n", stderr)
+	})
+}
+
+func TestApplyConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+	newFlags := func() (*flag.FlagSet, *solverFlags) {
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		return flags, addSolverFlags(flags)
+	}
+
+	t.Run("set the flags from a yaml file with lists", func(t *testing.T) {
+		flags, solver := newFlags()
+		err := applyConfig(flags, write("config.yaml", "segments: [business, vip]\ntime-precision: 3\nrouting: true\n"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "business,vip", *solver.customerSegments)
+		assert.Equal(t, 3, *solver.timePrecision)
+		assert.True(t, *solver.routing)
+	})
+	t.Run("set the flags from a json file", func(t *testing.T) {
+		flags, solver := newFlags()
+		err := applyConfig(flags, write("config.json", `{"packing-budget": "500ms", "shifts": ["08:00-12:00", "13:00-18:00"]}`))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "500ms", solver.packingBudget.String())
+		assert.Equal(t, "08:00-12:00,13:00-18:00", *solver.shifts)
+	})
+	t.Run("keep the flags of the command line", func(t *testing.T) {
+		flags, solver := newFlags()
+		assert.NoError(t, flags.Parse([]string{"--output", "csv"}))
+		err := applyConfig(flags, write("output.yaml", "output: json\n"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "csv", *solver.outputFormat)
+	})
+	t.Run("return error for an unknown option", func(t *testing.T) {
+		flags, _ := newFlags()
+		path := write("unknown.yaml", "speed: 70\n")
+		err := applyConfig(flags, path)
+
+		assert.EqualError(t, err, "config error: Unknown option 'speed' in "+path)
+	})
+	t.Run("return error for a wrong value", func(t *testing.T) {
+		flags, _ := newFlags()
+		path := write("wrong.yaml", "time-precision: two\n")
+		err := applyConfig(flags, path)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "config error: Wrong value of 'time-precision' in "+path)
+	})
+}
+
+func TestRunOffers(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("list the offers of the default catalog", func(t *testing.T) {
+		status, stdout, _ := runCommand(t, "offers", []string{"list"}, "")
+
+		assert.Equal(t, 0, status)
+		assert.Equal(t, "ID      TYPE     DISCOUNT  DISTANCE  WEIGHT   RULE\n"+
+			"OFR001  percent  10%       0-199     70-200   -\n"+
+			"OFR002  percent  7%        50-150    100-250  -\n"+
+			"OFR003  percent  5%        50-250    10-150   -\n"+
+			"Stacking: sum\n", stdout)
+	})
+	t.Run("list the offers as json", func(t *testing.T) {
+		status, stdout, _ := runCommand(t, "offers", []string{"list", "--output", "json"}, "")
+
+		assert.Equal(t, 0, status)
+		assert.Contains(t, stdout, `"id": "OFR001"`)
+	})
+	t.Run("validate every catalog and exit with status 1 for an invalid one", func(t *testing.T) {
+		valid := filepath.Join(dir, "valid.yaml")
+		invalid := filepath.Join(dir, "invalid.yaml")
+		assert.NoError(t, os.WriteFile(valid, []byte("offers:\n  - id: OFR010\n    percent: 15\n"), 0o644))
+		assert.NoError(t, os.WriteFile(invalid, []byte("offers:\n  - id: OFR010\n    percent: 150\n"), 0o644))
+		status, stdout, stderr := runCommand(t, "offers", []string{"validate", valid, invalid}, "")
+
+		assert.Equal(t, 1, status)
+		assert.Equal(t, valid+": 1 offers are valid\n", stdout)
+		assert.Equal(t, "load offer catalog error: "+invalid+":2: Offer OFR010 percent should be between 0 and 100\n", stderr)
+	})
+	t.Run("return error without a catalog to validate", func(t *testing.T) {
+		status, _, stderr := runCommand(t, "offers", []string{"validate"}, "")
+
+		assert.Equal(t, 2, status)
+		assert.Equal(t, "offers error: No offer catalog to validate, give its path\n", stderr)
+	})
+	t.Run("return error for an unknown offers command", func(t *testing.T) {
+		status, _, stderr := runCommand(t, "offers", []string{"remove"}, "")
+
+		assert.Equal(t, 2, status)
+		assert.Equal(t, "offers error: Unknown offers command 'remove', use list or validate\n", stderr)
+	})
+}
+
+func TestRunVersion(t *testing.T) {
+	t.Run("write the version of the app", func(t *testing.T) {
+		status, stdout, _ := runCommand(t, "version", nil, "")
+
+		assert.Equal(t, 0, status)
+		assert.True(t, strings.HasPrefix(stdout, "lets_help_kiki dev (go"), stdout)
+	})
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// An amount of money in hundredths of the currency unit, so sums and comparisons are exact
+type Money int64
+
+// The number of Money units in one currency unit
+const moneyScale = 100
+
+// The number of fixed-point units in one weight or distance unit, weights and distances have at most 3 decimals
+const measureScale = 1000
+
+// How an amount is rounded to a whole number of Money units
+type RoundingMode string
+
+const (
+	RoundHalfUp   RoundingMode = "half-up"   // Halves are rounded away from zero
+	RoundHalfEven RoundingMode = "half-even" // Halves are rounded to the even neighbour (banker's rounding)
+	RoundFloor    RoundingMode = "floor"     // Everything is rounded down
+)
+
+// Function to return the amount of money of whole currency units
+func moneyOf(units int) Money {
+	return Money(units * moneyScale)
+}
+
+// Function to convert a decimal amount of currency units to money, rounded to the nearest Money unit
+func moneyFromFloat(units float64) Money {
+	return Money(math.Round(units * moneyScale))
+}
+
+// Function to parse a decimal amount like "100", "-2.5" or "99.99"
+func parseMoney(input string) (Money, error) {
+	negative := strings.HasPrefix(input, "-")
+	whole, fraction, hasFraction := strings.Cut(strings.TrimPrefix(input, "-"), ".")
+	if whole == "" || (hasFraction && (fraction == "" || len(fraction) > 2)) {
+		return 0, fmt.Errorf("'%s' is not an amount with at most 2 decimals", input)
+	}
+	for len(fraction) < 2 {
+		fraction += "0"
+	}
+	for _, r := range whole + fraction {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("'%s' is not an amount with at most 2 decimals", input)
+		}
+	}
+
+	value, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not an amount with at most 2 decimals", input)
+	}
+	if negative {
+		value = -value
+	}
+	return Money(value), nil
+}
+
+// Function to format the amount with 2 decimals, whole amounts are written without decimals
+func (m Money) String() string {
+	sign, value := "", int64(m)
+	if value < 0 {
+		sign, value = "-", -value
+	}
+	if value%moneyScale == 0 {
+		return fmt.Sprintf("%s%d", sign, value/moneyScale)
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, value/moneyScale, value%moneyScale)
+}
+
+// Function to return the amount as a float, only for comparisons with whole number bounds
+func (m Money) float() float64 {
+	return float64(m) / moneyScale
+}
+
+// Function to calculate percent of the amount, rounded with the given mode
+func (m Money) percent(percent int, mode RoundingMode) Money {
+	return Money(divRound(int64(m)*int64(percent), 100, mode))
+}
+
+// Function to scale the amount by numerator/denominator, rounded with the given mode
+func (m Money) scale(numerator int64, denominator int64, mode RoundingMode) Money {
+	return Money(divRound(int64(m)*numerator, denominator, mode))
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// A JSON null keeps the amount as it is, like encoding/json does for the numbers
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	value, err := parseMoney(strings.Trim(string(data), "\""))
+	if err != nil {
+		return err
+	}
+	*m = value
+	return nil
+}
+
+func (m *Money) UnmarshalYAML(node *yaml.Node) error {
+	value, err := parseMoney(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	*m = value
+	return nil
+}
+
+// Function to divide and round the quotient with the given mode, the denominator should be positive
+func divRound(numerator int64, denominator int64, mode RoundingMode) int64 {
+	quotient, remainder := numerator/denominator, numerator%denominator
+	if remainder < 0 {
+		// Go truncates toward zero, keep the quotient as the floor and the remainder positive
+		quotient, remainder = quotient-1, remainder+denominator
+	}
+
+	switch mode {
+	case RoundFloor:
+		return quotient
+	case RoundHalfEven:
+		if 2*remainder > denominator || (2*remainder == denominator && quotient%2 != 0) {
+			return quotient + 1
+		}
+		return quotient
+	default:
+		// Away from zero: a negative half goes down, which is the floor that is already there
+		if 2*remainder > denominator || (2*remainder == denominator && numerator >= 0) {
+			return quotient + 1
+		}
+		return quotient
+	}
+}
+
+// Function to check a rounding mode, an empty mode is half-up
+func validRoundingMode(mode RoundingMode) bool {
+	switch mode {
+	case "", RoundHalfUp, RoundHalfEven, RoundFloor:
+		return true
+	}
+	return false
+}
+
+// Function to convert a weight or distance to fixed-point units, so packing sums are exact
+func milliUnits(measure float64) int {
+	return int(math.Round(measure * measureScale))
+}
+
+// Function to parse a weight or distance with at most 3 decimals
+func parseMeasure(input string) (float64, error) {
+	if _, fraction, hasFraction := strings.Cut(input, "."); hasFraction && len(fraction) > 3 {
+		return 0, fmt.Errorf("'%s' has more than 3 decimals", input)
+	}
+	value, err := strconv.ParseFloat(input, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("'%s' is not a number", input)
+	}
+	return value, nil
+}
+
+// Function to format a weight or distance without trailing zeros
+func formatMeasure(measure float64) string {
+	return strconv.FormatFloat(measure, 'f', -1, 64)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseMoney(t *testing.T) {
+	t.Run("return the amount in cents", func(t *testing.T) {
+		for input, expected := range map[string]Money{"100": 10000, "99.99": 9999, "2.5": 250, "-2.5": -250, "0.05": 5} {
+			money, err := parseMoney(input)
+
+			assert.NoError(t, err, input)
+			assert.Equal(t, expected, money, input)
+		}
+	})
+	t.Run("return error for amounts which are not decimals with at most 2 decimals", func(t *testing.T) {
+		for _, input := range []string{"", "-", "1.", ".5", "1.234", "10s", "1e3", "1,5"} {
+			_, err := parseMoney(input)
+
+			assert.Error(t, err, input)
+		}
+	})
+}
+
+func TestMoneyString(t *testing.T) {
+	t.Run("write whole amounts without decimals", func(t *testing.T) {
+		assert.Equal(t, "665", moneyOf(665).String())
+		assert.Equal(t, "0", Money(0).String())
+	})
+	t.Run("write other amounts with 2 decimals", func(t *testing.T) {
+		assert.Equal(t, "95.76", Money(9576).String())
+		assert.Equal(t, "0.05", Money(5).String())
+		assert.Equal(t, "-2.50", Money(-250).String())
+	})
+}
+
+func TestMoneyEncoding(t *testing.T) {
+	t.Run("encode and decode JSON numbers", func(t *testing.T) {
+		data, err := json.Marshal(struct{ Cost Money }{Money(9576)})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"Cost": 95.76}`, string(data))
+
+		var decoded struct{ Cost Money }
+		assert.NoError(t, json.Unmarshal([]byte(`{"Cost": 12.5}`), &decoded))
+		assert.Equal(t, Money(1250), decoded.Cost)
+	})
+	t.Run("keep the amount for a JSON null", func(t *testing.T) {
+		decoded := struct{ Cost Money }{Money(700)}
+		assert.NoError(t, json.Unmarshal([]byte(`{"Cost": null}`), &decoded))
+		assert.Equal(t, Money(700), decoded.Cost)
+	})
+	t.Run("decode YAML values with their line", func(t *testing.T) {
+		var decoded struct {
+			Cost Money `yaml:"cost"`
+		}
+		assert.NoError(t, yaml.Unmarshal([]byte("cost: 7.25"), &decoded))
+		assert.Equal(t, Money(725), decoded.Cost)
+
+		assert.EqualError(t, yaml.Unmarshal([]byte("\ncost: 7.255"), &decoded), "line 2: '7.255' is not an amount with at most 2 decimals")
+	})
+}
+
+func TestDivRound(t *testing.T) {
+	t.Run("round with each mode", func(t *testing.T) {
+		for _, test := range []struct {
+			numerator int64
+			mode      RoundingMode
+			expected  int64
+		}{
+			{25, RoundHalfUp, 3},
+			{35, RoundHalfUp, 4},
+			{-25, RoundHalfUp, -3},
+			{24, RoundHalfUp, 2},
+			{25, RoundHalfEven, 2},
+			{35, RoundHalfEven, 4},
+			{-25, RoundHalfEven, -2},
+			{26, RoundHalfEven, 3},
+			{29, RoundFloor, 2},
+			{-21, RoundFloor, -3},
+			{25, "", 3},
+		} {
+			assert.Equal(t, test.expected, divRound(test.numerator, 10, test.mode), "%d %s", test.numerator, test.mode)
+		}
+	})
+}
+
+func TestParseMeasure(t *testing.T) {
+	t.Run("return weights and distances with up to 3 decimals", func(t *testing.T) {
+		for input, expected := range map[string]float64{"50": 50, "50.5": 50.5, "0.125": 0.125} {
+			measure, err := parseMeasure(input)
+
+			assert.NoError(t, err, input)
+			assert.Equal(t, expected, measure, input)
+		}
+	})
+	t.Run("return error for other inputs", func(t *testing.T) {
+		for _, input := range []string{"50s", "0.1234", "NaN", "Inf", ""} {
+			_, err := parseMeasure(input)
+
+			assert.Error(t, err, input)
+		}
+	})
+	t.Run("sum milli units exactly", func(t *testing.T) {
+		assert.Equal(t, 300, milliUnits(0.1)+milliUnits(0.2))
+		assert.Equal(t, "50.5", formatMeasure(50.5))
+	})
+}
@@ -1,101 +1,236 @@
 package main
 
-import "fmt"
-
 type CalculationOutput struct {
-	TotalCost int
-	Discount  int
+	Title           string   `json:"title"`
+	Discount        Money    `json:"discount"`
+	TotalCost       Money    `json:"totalCost"`
+	AppliedOfferIds []string `json:"appliedOfferIds"` // The offers which matched the package and gave a discount
+	// The discount of each applied offer, in the order of AppliedOfferIds
+	AppliedOffers []AppliedOffer `json:"appliedOffers,omitempty"`
+}
+
+type AppliedOffer struct {
+	Id       string `json:"id"`
+	Type     string `json:"type"`
+	Discount Money  `json:"discount"`
+}
+
+// The parts of the delivery cost of a package before discounts
+type CostBreakdown struct {
+	BaseCost           Money
+	WeightCost         Money
+	DistanceCost       Money
+	FuelSurcharge      Money
+	PrioritySurcharge  Money // For the express and same-day packages
+	MinimumChargeTopUp Money // Added when the other parts cost less than the minimum charge
 }
 
 type CompareAmount struct {
-	GreaterThanEqual int
-	LessThanEqual    int
+	GreaterThanEqual int `json:"greaterThanEqual" yaml:"greaterThanEqual"`
+	LessThanEqual    int `json:"lessThanEqual" yaml:"lessThanEqual"`
 }
 
 type Offer struct {
-	Id       string
-	Distance CompareAmount
-	Weight   CompareAmount
-	Percent  int
+	Id       string        `json:"id" yaml:"id"`
+	Distance CompareAmount `json:"distance" yaml:"distance"`
+	Weight   CompareAmount `json:"weight" yaml:"weight"`
+	Type     string        `json:"type,omitempty" yaml:"type"` // percent (default), flat, tiered, freeDistance or perKg
+	Percent  int           `json:"percent" yaml:"percent"`
+	Amount   Money         `json:"amount,omitempty" yaml:"amount"` // The amount off (flat) or the rebate per weight unit (perKg), at most 2 decimals
+	// The free distance of a freeDistance offer, a measure with at most 3 decimals
+	FreeDistance float64     `json:"freeDistance,omitempty" yaml:"freeDistance"`
+	Tiers        []OfferTier `json:"tiers,omitempty" yaml:"tiers"` // The percent by package weight (tiered)
+	Rule         *OfferRule  `json:"rule,omitempty" yaml:"rule"`   // Optional eligibility rule, checked together with the ranges
+}
+
+// A tier of a tiered offer, it's used for packages up to UpToWeight (0 means no limit, only for the last tier)
+type OfferTier struct {
+	UpToWeight int `json:"upToWeight,omitempty" yaml:"upToWeight"`
+	Percent    int `json:"percent" yaml:"percent"`
+}
+
+const (
+	OfferPercent      = "percent"      // Percent of the delivery cost
+	OfferFlat         = "flat"         // A fixed amount off
+	OfferTiered       = "tiered"       // Percent of the delivery cost, picked by the package weight
+	OfferFreeDistance = "freeDistance" // The distance cost of the first FreeDistance distance units
+	OfferPerKg        = "perKg"        // Amount off for each weight unit of the package
+)
+
+func init() {
+	registerProblem(Problem{
+		Key:      "1",
+		Title:    "Delivery Cost Estimation with Offers",
+		Schema:   InputSchema{FirstLine: firstLineSchema, Package: packageLineSchema},
+		Solver:   CalculateDeliveryCost,
+		Route:    "cost",
+		Estimate: estimateCost,
+	})
 }
 
 // The solver function for the "Delivery Cost Estimation" problem
-func CalculateDeliveryCost(firstInputLine FirstLineInput, packageDetails []PackageDetail, extraDetails [][]string) ([]string, error) {
-	baseDeliveryCost := firstInputLine.BaseCost
+func CalculateDeliveryCost(firstInputLine FirstLineInput, packageDetails []PackageDetail, extraDetails [][]string, options SolverOptions) (Result, error) {
+	if err := validateProblem(firstInputLine, packageDetails); err != nil {
+		return Result{}, err
+	}
+	return estimateDeliveryCost(firstInputLine, packageDetails, options), nil
+}
 
-	outputs := []string{}
+// Function to calculate the cost of every package, in the input order
+func estimateDeliveryCost(firstInputLine FirstLineInput, packageDetails []PackageDetail, options SolverOptions) Result {
+	outputs := []CalculationOutput{}
 	for _, packageDetail := range packageDetails {
-		calculationOutput := calculateTotalCost(baseDeliveryCost, packageDetail)
-		outputs = append(outputs, fmt.Sprintf("%s %d %d", packageDetail.Title, calculationOutput.Discount, calculationOutput.TotalCost))
+		outputs = append(outputs, calculateTotalCost(firstInputLine, packageDetail, options))
 	}
-	return outputs, nil
+	return Result{Currency: options.activePricingModel().Currency, Costs: outputs}
 }
 
-// Function to calculate the total cost according to the base cost, weight, distance and discounts
-func calculateTotalCost(baseDeliveryCost int, packageDetail PackageDetail) CalculationOutput {
-	deliveryCost := baseDeliveryCost + (packageDetail.Weight * 10) + (packageDetail.Distance * 5)
-	discount := calculateDiscounts(packageDetail, deliveryCost)
+// Function to calculate the total cost according to the pricing model and discounts
+func calculateTotalCost(firstInputLine FirstLineInput, packageDetail PackageDetail, options SolverOptions) CalculationOutput {
+	pricingModel := options.activePricingModel()
+	breakdown := pricingModel.breakdown(firstInputLine.BaseCost, packageDetail)
+	deliveryCost := breakdown.total()
+
+	discount, appliedOffers := calculateDiscounts(RuleContext{
+		Package:          packageDetail,
+		DeliveryCost:     deliveryCost,
+		PackageCount:     firstInputLine.NumberOfPackages,
+		OrderDate:        options.OrderDate,
+		CustomerSegments: options.CustomerSegments,
+	}, breakdown, options.Offers, pricingModel.Rounding)
 
+	appliedOfferIds := []string{}
+	for _, appliedOffer := range appliedOffers {
+		appliedOfferIds = append(appliedOfferIds, appliedOffer.Id)
+	}
 	return CalculationOutput{
-		TotalCost: deliveryCost - discount,
-		Discount:  discount,
+		Title:           packageDetail.Title,
+		Discount:        discount,
+		TotalCost:       deliveryCost - discount,
+		AppliedOfferIds: appliedOfferIds,
+		AppliedOffers:   appliedOffers,
 	}
 }
 
-// Function to calculate discount for the package
-func calculateDiscounts(packageDetail PackageDetail, deliveryCost int) int {
-	offers := []Offer{
-		{
-			Id: "OFR001",
-			Distance: CompareAmount{
-				GreaterThanEqual: 0,
-				LessThanEqual:    199,
-			},
-			Weight: CompareAmount{
-				GreaterThanEqual: 70,
-				LessThanEqual:    200,
-			},
-			Percent: 10,
-		},
-		{
-			Id: "OFR002",
-			Distance: CompareAmount{
-				GreaterThanEqual: 50,
-				LessThanEqual:    150,
-			},
-			Weight: CompareAmount{
-				GreaterThanEqual: 100,
-				LessThanEqual:    250,
-			},
-			Percent: 7,
-		},
-		{
-			Id: "OFR003",
-			Distance: CompareAmount{
-				GreaterThanEqual: 50,
-				LessThanEqual:    250,
-			},
-			Weight: CompareAmount{
-				GreaterThanEqual: 10,
-				LessThanEqual:    150,
-			},
-			Percent: 5,
-		},
+func (breakdown CostBreakdown) total() Money {
+	return breakdown.BaseCost + breakdown.WeightCost + breakdown.DistanceCost + breakdown.FuelSurcharge + breakdown.PrioritySurcharge + breakdown.MinimumChargeTopUp
+}
+
+// Function to calculate discount for the package and return the applied offers with their discounts
+// The matching offers are combined with the stacking policy of the catalog, an offer id given twice is applied once
+// Every discount is rounded to whole Money units with the rounding mode of the pricing model
+func calculateDiscounts(context RuleContext, breakdown CostBreakdown, catalog OfferCatalog, rounding RoundingMode) (Money, []AppliedOffer) {
+	deliveryCost := breakdown.total()
+	matchedOffers := []Offer{}
+	for _, offerId := range context.Package.OfferIds {
+		for _, offer := range catalog.Offers {
+			if offer.Id == offerId && !containsOffer(matchedOffers, offer.Id) && offer.eligibility().matches(context) {
+				matchedOffers = append(matchedOffers, offer)
+			}
+		}
 	}
 
-	discount := 0
-	for _, offerId := range packageDetail.OfferIds {
-		for _, offer := range offers {
-			if offer.Id == offerId {
-				if (offer.Weight.GreaterThanEqual == 0 || packageDetail.Weight >= offer.Weight.GreaterThanEqual) &&
-					(offer.Weight.LessThanEqual == 0 || packageDetail.Weight <= offer.Weight.LessThanEqual) &&
-					(offer.Distance.GreaterThanEqual == 0 || packageDetail.Distance >= offer.Distance.GreaterThanEqual) &&
-					(offer.Distance.LessThanEqual == 0 || packageDetail.Distance <= offer.Distance.LessThanEqual) {
-
-					discount += deliveryCost * offer.Percent / 100
-				}
+	appliedOffers := []AppliedOffer{}
+	switch catalog.Stacking.Policy {
+	case StackingBest:
+		for _, offer := range matchedOffers {
+			discount := offer.discount(context.Package, breakdown, deliveryCost, rounding)
+			if len(appliedOffers) == 0 || discount > appliedOffers[0].Discount {
+				appliedOffers = []AppliedOffer{{Id: offer.Id, Type: offer.offerType(), Discount: discount}}
 			}
 		}
+	case StackingCompound:
+		remainedCost := deliveryCost
+		for _, offer := range matchedOffers {
+			discount := offer.discount(context.Package, breakdown, remainedCost, rounding)
+			remainedCost -= discount
+			appliedOffers = append(appliedOffers, AppliedOffer{Id: offer.Id, Type: offer.offerType(), Discount: discount})
+		}
+	default:
+		for _, offer := range matchedOffers {
+			discount := offer.discount(context.Package, breakdown, deliveryCost, rounding)
+			appliedOffers = append(appliedOffers, AppliedOffer{Id: offer.Id, Type: offer.offerType(), Discount: discount})
+		}
+	}
+
+	// Limit the total discount, the offers applied last give up their discount first
+	// and the offers left without discount aren't reported as applied
+	remainedDiscount := maxDiscount(deliveryCost, catalog.Stacking, rounding)
+	totalDiscount := Money(0)
+	givenOffers := []AppliedOffer{}
+	for _, appliedOffer := range appliedOffers {
+		if appliedOffer.Discount > remainedDiscount {
+			appliedOffer.Discount = remainedDiscount
+		}
+		if appliedOffer.Discount == 0 {
+			continue
+		}
+		remainedDiscount -= appliedOffer.Discount
+		totalDiscount += appliedOffer.Discount
+		givenOffers = append(givenOffers, appliedOffer)
+	}
+	if len(givenOffers) == 0 {
+		return totalDiscount, nil
+	}
+	return totalDiscount, givenOffers
+}
+
+// Function to calculate the discount of an offer
+// Percents are taken from cost, which is the delivery cost left by the offers applied before, and no discount is bigger than cost
+func (offer Offer) discount(packageDetail PackageDetail, breakdown CostBreakdown, cost Money, rounding RoundingMode) Money {
+	discount := Money(0)
+	switch offer.offerType() {
+	case OfferPercent:
+		discount = cost.percent(offer.Percent, rounding)
+	case OfferFlat:
+		discount = offer.Amount
+	case OfferTiered:
+		for _, tier := range offer.Tiers {
+			if tier.UpToWeight == 0 || packageDetail.Weight <= float64(tier.UpToWeight) {
+				discount = cost.percent(tier.Percent, rounding)
+				break
+			}
+		}
+	case OfferFreeDistance:
+		if distance := milliUnits(packageDetail.Distance); distance > 0 {
+			freeDistance := minInt(milliUnits(offer.FreeDistance), distance)
+			discount = breakdown.DistanceCost.scale(int64(freeDistance), int64(distance), rounding)
+		}
+	case OfferPerKg:
+		discount = offer.Amount.scale(int64(milliUnits(packageDetail.Weight)), measureScale, rounding)
+	}
+	if discount > cost {
+		return cost
 	}
 	return discount
 }
+
+// Function to return the type of an offer, offers without a type are percent offers
+func (offer Offer) offerType() string {
+	if offer.Type == "" {
+		return OfferPercent
+	}
+	return offer.Type
+}
+
+// Function to find the max total discount of a package by the max percent and max amount of the stacking policy
+// A limit of 0 is not checked, the discount is never bigger than the delivery cost
+func maxDiscount(deliveryCost Money, stacking StackingPolicy, rounding RoundingMode) Money {
+	limit := deliveryCost
+	if capped := deliveryCost.percent(stacking.MaxPercent, rounding); stacking.MaxPercent != 0 && capped < limit {
+		limit = capped
+	}
+	if stacking.MaxAmount != 0 && stacking.MaxAmount < limit {
+		limit = stacking.MaxAmount
+	}
+	return limit
+}
+
+func containsOffer(offers []Offer, offerId string) bool {
+	for _, offer := range offers {
+		if offer.Id == offerId {
+			return true
+		}
+	}
+	return false
+}
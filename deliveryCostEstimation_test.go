@@ -8,7 +8,7 @@ import (
 
 func TestCalculateDeliveryCost(t *testing.T) {
 	firstLineInput := FirstLineInput{
-		BaseCost:         100,
+		BaseCost:         moneyOf(100),
 		NumberOfPackages: 3,
 	}
 
@@ -23,10 +23,12 @@ func TestCalculateDeliveryCost(t *testing.T) {
 			},
 		}
 
-		outputs, err := CalculateDeliveryCost(firstLineInput, packageDetails, nil)
+		result, err := CalculateDeliveryCost(firstLineInput, packageDetails, nil, SolverOptions{Offers: defaultOfferCatalog()})
 
 		assert.NoError(t, err)
-		assert.Equal(t, []string{"PKG1 0 175"}, outputs)
+		assert.Equal(t, Result{Costs: []CalculationOutput{
+			{Title: "PKG1", Discount: moneyOf(0), TotalCost: moneyOf(175), AppliedOfferIds: []string{}},
+		}}, result)
 	})
 
 	t.Run("return correct discount for valid offerId", func(t *testing.T) {
@@ -39,9 +41,135 @@ func TestCalculateDeliveryCost(t *testing.T) {
 				OfferIds: []string{"OFR003"},
 			},
 		}
-		outputs, err := CalculateDeliveryCost(firstLineInput, packageDetails, nil)
+		result, err := CalculateDeliveryCost(firstLineInput, packageDetails, nil, SolverOptions{Offers: defaultOfferCatalog()})
 
 		assert.NoError(t, err)
-		assert.Equal(t, []string{"PKG3 35 665"}, outputs)
+		assert.Equal(t, Result{Costs: []CalculationOutput{
+			{Title: "PKG3", Discount: moneyOf(35), TotalCost: moneyOf(665), AppliedOfferIds: []string{"OFR003"}, AppliedOffers: []AppliedOffer{{Id: "OFR003", Type: OfferPercent, Discount: moneyOf(35)}}},
+		}}, result)
+	})
+
+	t.Run("use the offers of the given catalog", func(t *testing.T) {
+		packageDetails := []PackageDetail{
+			{
+				Index:    0,
+				Title:    "PKG1",
+				Weight:   5,
+				Distance: 5,
+				OfferIds: []string{"OFR100"},
+			},
+		}
+		options := SolverOptions{Offers: OfferCatalog{Offers: []Offer{{Id: "OFR100", Percent: 20}}}}
+		result, err := CalculateDeliveryCost(firstLineInput, packageDetails, nil, options)
+
+		assert.NoError(t, err)
+		assert.Equal(t, Result{Costs: []CalculationOutput{
+			{Title: "PKG1", Discount: moneyOf(35), TotalCost: moneyOf(140), AppliedOfferIds: []string{"OFR100"}, AppliedOffers: []AppliedOffer{{Id: "OFR100", Type: OfferPercent, Discount: moneyOf(35)}}},
+		}}, result)
+	})
+
+	t.Run("combine the offers of a package with the stacking policy", func(t *testing.T) {
+		packageDetails := []PackageDetail{
+			{Index: 0, Title: "PKG1", Weight: 100, Distance: 100, OfferIds: []string{"OFR001", "OFR003", "OFR001", "OFR002"}},
+		}
+		// The delivery cost is 100 + 100*10 + 100*5 = 1600, OFR001 (10%), OFR002 (7%) and OFR003 (5%) apply
+		for _, test := range []struct {
+			stacking  StackingPolicy
+			discount  string
+			discounts []string
+		}{
+			{StackingPolicy{}, "352", []string{"160", "80", "112"}},
+			{StackingPolicy{Policy: StackingBest}, "160", []string{"160"}},
+			{StackingPolicy{Policy: StackingCompound}, "327.76", []string{"160", "72", "95.76"}},
+			{StackingPolicy{Policy: StackingSum, MaxPercent: 15}, "240", []string{"160", "80"}},
+			{StackingPolicy{Policy: StackingSum, MaxAmount: moneyOf(200)}, "200", []string{"160", "40"}},
+		} {
+			catalog := defaultOfferCatalog()
+			catalog.Stacking = test.stacking
+			result, err := CalculateDeliveryCost(firstLineInput, packageDetails, nil, SolverOptions{Offers: catalog})
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.discount, result.Costs[0].Discount.String(), test.stacking.Policy)
+			assert.Equal(t, moneyOf(1600)-result.Costs[0].Discount, result.Costs[0].TotalCost, test.stacking.Policy)
+			discounts := []string{}
+			for _, appliedOffer := range result.Costs[0].AppliedOffers {
+				discounts = append(discounts, appliedOffer.Discount.String())
+			}
+			assert.Equal(t, test.discounts, discounts, test.stacking.Policy)
+			assert.Equal(t, []string{"OFR001", "OFR003", "OFR002"}[:len(test.discounts)], result.Costs[0].AppliedOfferIds, test.stacking.Policy)
+		}
+	})
+
+	t.Run("calculate the discount of each offer type", func(t *testing.T) {
+		catalog := OfferCatalog{Offers: []Offer{
+			{Id: "FLAT100", Type: OfferFlat, Amount: moneyOf(100)},
+			{Id: "TIERED", Type: OfferTiered, Tiers: []OfferTier{{UpToWeight: 100, Percent: 5}, {Percent: 8}}},
+			{Id: "FREE20KM", Type: OfferFreeDistance, FreeDistance: 20},
+			{Id: "KG2", Type: OfferPerKg, Amount: moneyOf(2)},
+			{Id: "FLAT5000", Type: OfferFlat, Amount: moneyOf(5000)},
+		}}
+		// The delivery costs are 100 + 50*10 + 100*5 = 1100 and 100 + 150*10 + 10*5 = 1650
+		packageDetails := []PackageDetail{
+			{Index: 0, Title: "PKG1", Weight: 50, Distance: 100, OfferIds: []string{"FLAT100", "TIERED", "FREE20KM", "KG2"}},
+			{Index: 1, Title: "PKG2", Weight: 150, Distance: 10, OfferIds: []string{"TIERED", "FREE20KM", "FLAT5000"}},
+		}
+		result, err := CalculateDeliveryCost(firstLineInput, packageDetails, nil, SolverOptions{Offers: catalog})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []AppliedOffer{
+			{Id: "FLAT100", Type: OfferFlat, Discount: moneyOf(100)},
+			{Id: "TIERED", Type: OfferTiered, Discount: moneyOf(55)},
+			{Id: "FREE20KM", Type: OfferFreeDistance, Discount: moneyOf(100)},
+			{Id: "KG2", Type: OfferPerKg, Discount: moneyOf(100)},
+		}, result.Costs[0].AppliedOffers)
+		assert.Equal(t, moneyOf(745), result.Costs[0].TotalCost)
+		// The discount is never bigger than the delivery cost
+		assert.Equal(t, []AppliedOffer{
+			{Id: "TIERED", Type: OfferTiered, Discount: moneyOf(132)},
+			{Id: "FREE20KM", Type: OfferFreeDistance, Discount: moneyOf(50)},
+			{Id: "FLAT5000", Type: OfferFlat, Discount: moneyOf(1468)},
+		}, result.Costs[1].AppliedOffers)
+		assert.Equal(t, moneyOf(0), result.Costs[1].TotalCost)
+	})
+
+	t.Run("apply the offers whose rule matches the order", func(t *testing.T) {
+		catalog := OfferCatalog{Offers: []Offer{
+			{Id: "SPRING", Percent: 10, Rule: &OfferRule{ValidFrom: "2024-03-01", ValidUntil: "2024-05-31"}},
+			{Id: "VIP", Percent: 5, Rule: &OfferRule{Segments: []string{"vip"}}},
+		}}
+		packageDetails := []PackageDetail{{Index: 0, Title: "PKG1", Weight: 5, Distance: 5, OfferIds: []string{"SPRING", "VIP"}}}
+
+		options := SolverOptions{Offers: catalog}
+		assert.NoError(t, setOrderOptions(&options, "2024-04-01", []string{"vip"}))
+		result, _ := CalculateDeliveryCost(firstLineInput, packageDetails, nil, options)
+		assert.Equal(t, []string{"SPRING", "VIP"}, result.Costs[0].AppliedOfferIds)
+
+		assert.NoError(t, setOrderOptions(&options, "2024-06-01", nil))
+		result, _ = CalculateDeliveryCost(firstLineInput, packageDetails, nil, options)
+		assert.Equal(t, []string{}, result.Costs[0].AppliedOfferIds)
+	})
+
+	t.Run("round the discount of decimal measures with the rounding of the pricing model", func(t *testing.T) {
+		// The delivery cost is 100 + 10.5*10 + 100.3*5 = 706.50, OFR003 (5%) gives 35.325
+		packageDetails := []PackageDetail{{Index: 0, Title: "PKG1", Weight: 10.5, Distance: 100.3, OfferIds: []string{"OFR003"}}}
+		for _, test := range []struct {
+			rounding  RoundingMode
+			discount  string
+			totalCost string
+		}{
+			{RoundHalfUp, "35.33", "671.17"},
+			{RoundHalfEven, "35.32", "671.18"},
+			{RoundFloor, "35.32", "671.18"},
+		} {
+			pricingModel := defaultPricingModel()
+			pricingModel.Rounding = test.rounding
+			pricingModel.Currency = "EUR"
+			result, err := CalculateDeliveryCost(firstLineInput, packageDetails, nil, SolverOptions{Offers: defaultOfferCatalog(), PricingModel: pricingModel})
+
+			assert.NoError(t, err)
+			assert.Equal(t, "EUR", result.Currency)
+			assert.Equal(t, test.discount, result.Costs[0].Discount.String(), test.rounding)
+			assert.Equal(t, test.totalCost, result.Costs[0].TotalCost.String(), test.rounding)
+		}
 	})
 }
@@ -1,82 +1,322 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
-	"math"
 	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Subset struct {
-	TotalWeight          int   // The sum of packages weight in the subset
-	MaxDistance          int   // The maximum distance of the packages in the subset
-	PackageDetailIndices []int // List of ids for removing them from the main array after shipment
+	TotalWeight          float64 // The sum of packages weight in the subset
+	MaxDistance          float64 // The maximum distance of the packages in the subset
+	PackageDetailIndices []int   // List of ids for removing them from the main array after shipment
 	PackageDetails       []PackageDetail
+	Vehicle              int     // The number of the vehicle taking the shipment, starting from 1
+	Trip                 int     // The number of the vehicle's trip, starting from 1
+	DepartureTime        float64 // The time the vehicle leaves with the shipment
+	ReturnTime           float64 // The time the vehicle is back after the shipment
+	// The distance driven to each package by its index and the length of the round trip, only in the routing mode
+	StopDistances map[int]float64
+	RouteLength   float64
 }
 
 type ExtraDetails struct {
-	NumberOfVehicles   int
-	MaxSpeed           int
-	MaxCarriableWeight int
+	NumberOfVehicles   int       `json:"numberOfVehicles,omitempty"`
+	MaxSpeed           int       `json:"maxSpeed,omitempty"`
+	MaxCarriableWeight int       `json:"maxCarriableWeight,omitempty"`
+	Vehicles           []Vehicle `json:"vehicles,omitempty"` // Per vehicle speed and weight, the fields above are not used when it's set
 }
 
 type ShipmentDetail struct {
-	Title        string
-	Discount     int
-	TotalCost    int
-	DeliveryTime float64
+	CalculationOutput
+	DeliveryTime float64 `json:"deliveryTime"`         // In the time unit of the result
+	DeliveryAt   string  `json:"deliveryAt,omitempty"` // The wall-clock delivery time, for the clock time unit
+	Vehicle      int     `json:"vehicle"`              // The number of the vehicle delivering the package, starting from 1
+	VehicleId    string  `json:"vehicleId"`            // The id of the vehicle in the fleet definition
+	Trip         int     `json:"trip"`                 // The number of the vehicle's trip delivering the package, starting from 1
+	// The package is heavier than any vehicle can carry, it has no delivery time, vehicle or trip
+	Undeliverable bool `json:"undeliverable,omitempty"`
+}
+
+// The packages which are heavier than the biggest vehicle of the fleet can carry
+type UnshippableError struct {
+	MaxCarriableWeight int
+	Packages           []PackageDetail
+}
+
+func (e *UnshippableError) Error() string {
+	packages := []string{}
+	for _, p := range e.Packages {
+		packages = append(packages, fmt.Sprintf("%s (%s)", p.Title, formatMeasure(p.Weight)))
+	}
+	return fmt.Sprintf("Validate shipment error: %d package(s) heavier than the max carriable weight %d: %s",
+		len(e.Packages), e.MaxCarriableWeight, strings.Join(packages, ", "))
+}
+
+// One trip of a vehicle in the dispatch plan
+type Dispatch struct {
+	Vehicle       int      `json:"vehicle"`
+	VehicleId     string   `json:"vehicleId"`
+	Trip          int      `json:"trip"`
+	DepartureTime float64  `json:"departureTime"` // In the time unit of the result
+	ReturnTime    float64  `json:"returnTime"`
+	DepartureAt   string   `json:"departureAt,omitempty"` // The wall-clock times, for the clock time unit
+	ReturnAt      string   `json:"returnAt,omitempty"`
+	Packages      []string `json:"packages"`              // The package titles in delivery order
+	RouteLength   float64  `json:"routeLength,omitempty"` // The length of the round trip, only in the routing mode
+}
+
+// The settings of the trip planning
+type planSettings struct {
+	Deadline            time.Time // The end of the exact packing time budget, zero means no limit
+	TimeModel           TimeModel
+	Schedule            Schedule
+	Windows             map[int]timeWindow // The delivery windows by package index
+	PrioritizeDeadlines bool               // Take the packages which would miss their due time on a later trip first
+	Routing             bool               // Drive the shipments along a route through the package locations
+	Objective           PackingObjective   // How the packages of a shipment are picked, nil is the objective of the problem statement
+	Revenues            map[int]Money      // The total cost of the packages by index, for the revenue objective
+}
+
+// Function to return the packing objective of the plan, the one of the problem statement if none is set
+func (plan planSettings) objective() PackingObjective {
+	if plan.Objective == nil {
+		return packingObjectives[defaultPackingObjective]
+	}
+	return plan.Objective
+}
+
+// The time a vehicle comes back and can take the next shipment
+type vehicleAvailability struct {
+	Vehicle     int
+	AvailableAt float64
+	Trips       int
+}
+
+// The extra line of the "Delivery Time Estimation" problem, read by validateExtraDetails
+var shipmentLineSchema = LineSchema{
+	Name:      "shipment detail",
+	APIObject: "extraDetails",
+	Fields: []InputField{
+		{Name: "numberOfVehicles", Type: FieldInteger, Description: "number of vehicles, all alike", Example: "2",
+			APIType: "integer", APIExample: json.RawMessage(`2`)},
+		{Name: "maxSpeed", Type: FieldInteger, Description: "speed of the vehicles in km/h", Example: "70",
+			APIType: "integer", APIExample: json.RawMessage(`70`)},
+		{Name: "maxCarriableWeight", Type: FieldInteger, Description: "max load of a vehicle in kg", Example: "200",
+			APIType: "integer", APIExample: json.RawMessage(`200`)},
+	},
+	Alternatives: []string{
+		"one id:maxSpeed:maxCarriableWeight token per vehicle, e.g. VAN1:70:200 BIKE1:30:20",
+		"@path of a fleet file, e.g. @fleet.example.yaml",
+	},
+	APIAlternatives: []InputField{
+		{Name: "vehicles", Type: FieldList, Description: "vehicles with their own id, speed, max load and shifts", Example: "VAN1:70:200 BIKE1:30:20",
+			APIType: "array", APIExample: json.RawMessage(`[{"id": "VAN1", "maxSpeed": 70, "maxCarriableWeight": 200}, {"id": "BIKE1", "maxSpeed": 30, "maxCarriableWeight": 20}]`)},
+	},
+}
+
+func init() {
+	registerProblem(Problem{
+		Key:   "2",
+		Title: "Delivery Time Estimation",
+		Schema: InputSchema{
+			FirstLine:  firstLineSchema,
+			Package:    packageLineSchema,
+			ExtraLines: []LineSchema{shipmentLineSchema},
+		},
+		Solver:             CalculateDeliveryTime,
+		ValidateExtraLines: checkExtraDetails,
+		Route:              "time",
+		Estimate:           estimateTime,
+	})
 }
 
 // The solver function for the "Delivery Time Estimation" problem
-func CalculateDeliveryTime(firstInputLine FirstLineInput, packageDetails []PackageDetail, extraDetails [][]string) ([]string, error) {
+func CalculateDeliveryTime(firstInputLine FirstLineInput, packageDetails []PackageDetail, extraDetails [][]string, options SolverOptions) (Result, error) {
+	if err := validateProblem(firstInputLine, packageDetails); err != nil {
+		return Result{}, err
+	}
 
 	validatedExtraDetails, err := validateExtraDetails(extraDetails)
 	if err != nil {
-		return nil, err
+		return Result{}, err
+	}
+
+	return estimateDeliveryTime(firstInputLine, packageDetails, validatedExtraDetails, options)
+}
+
+// Function to plan the shipments and create a shipmentDetail object for each package in the input order,
+// and a dispatch for each shipment in the departure order
+func estimateDeliveryTime(firstInputLine FirstLineInput, packageDetails []PackageDetail, extraDetails ExtraDetails, options SolverOptions) (Result, error) {
+	if err := validateFleet(extraDetails); err != nil {
+		return Result{}, err
 	}
+	fleet := extraDetails.fleet()
 
-	sort.Slice(packageDetails, func(i, j int) bool {
+	packageDetails, unshippable := splitUnshippable(packageDetails, maxFleetCapacity(fleet))
+	if len(unshippable) > 0 && !options.SkipUnshippable {
+		return Result{}, &UnshippableError{MaxCarriableWeight: maxFleetCapacity(fleet), Packages: unshippable}
+	}
+
+	sort.SliceStable(packageDetails, func(i, j int) bool {
 		return packageDetails[i].Weight < packageDetails[j].Weight
 	})
 
-	shipmentSubsets := make([]Subset, 0)
-	getShipmentSubsets(packageDetails, validatedExtraDetails.MaxCarriableWeight, &shipmentSubsets)
+	plan := planSettings{
+		TimeModel:           options.activeTimeModel(),
+		PrioritizeDeadlines: options.PrioritizeDeadlines,
+		Routing:             options.Routing,
+		Objective:           options.activeObjective(),
+		Revenues:            map[int]Money{},
+	}
+	for _, packageDetail := range packageDetails {
+		plan.Revenues[packageDetail.Index] = calculateTotalCost(firstInputLine, packageDetail, options).TotalCost
+	}
+	if plan.Routing {
+		if err := checkLocations(packageDetails); err != nil {
+			return Result{}, err
+		}
+	}
+	if options.PackingTimeBudget > 0 {
+		plan.Deadline = time.Now().Add(options.PackingTimeBudget)
+	}
+	schedule, err := newSchedule(fleet, options)
+	if err != nil {
+		return Result{}, err
+	}
+	plan.Schedule = schedule
+	if !schedule.Start.IsZero() && plan.TimeModel.Unit == TimeHours {
+		// The shifts are wall-clock times, so are the delivery times
+		plan.TimeModel.Unit = TimeClock
+		options.TimeModel = plan.TimeModel
+	}
+	if plan.Windows, err = packageWindows(append(packageDetails, unshippable...), plan.TimeModel.DispatchStart); err != nil {
+		return Result{}, err
+	}
+	shipmentSubsets, err := getShipmentSubsets(packageDetails, fleet, plan)
+	if err != nil {
+		return Result{}, err
+	}
+	var optimization *OptimizationReport
+	if options.Optimizer.Enabled {
+		var report OptimizationReport
+		shipmentSubsets, report = optimizeShipments(shipmentSubsets, fleet, plan, options.Optimizer)
+		optimization = &report
+	}
 
-	shipmentDetails := calculateShipmentDetails(firstInputLine, shipmentSubsets, validatedExtraDetails)
-	outputs := []string{}
-	for _, o := range shipmentDetails {
-		outputs = append(outputs, fmt.Sprintf("%s %d %d %.2f", o.Title, o.Discount, o.TotalCost, o.DeliveryTime))
+	shipmentDetails := calculateShipmentDetails(firstInputLine, shipmentSubsets, fleet, options)
+	for _, d := range unshippable {
+		shipmentDetails[d.Index] = ShipmentDetail{
+			CalculationOutput: calculateTotalCost(firstInputLine, d, options),
+			Undeliverable:     true,
+		}
 	}
 
-	return outputs, nil
+	return Result{
+		Currency:     options.activePricingModel().Currency,
+		TimeUnit:     plan.TimeModel.Unit,
+		Shipments:    shipmentDetails,
+		Dispatches:   calculateDispatches(shipmentSubsets, fleet, plan.TimeModel),
+		Sla:          slaReport(shipmentSubsets, fleet, unshippable, plan),
+		Optimization: optimization,
+	}, nil
 }
 
-// Function to get all shipment subsets
-func getShipmentSubsets(packages []PackageDetail, maxCarriableWeight int, result *[]Subset) {
-	maxSize := findMaxSubsetSize(packages, maxCarriableWeight)
+// Function to separate the packages which are heavier than the max carriable weight
+func splitUnshippable(packageDetails []PackageDetail, maxCarriableWeight int) ([]PackageDetail, []PackageDetail) {
+	shippable := make([]PackageDetail, 0, len(packageDetails))
+	unshippable := []PackageDetail{}
+	for _, packageDetail := range packageDetails {
+		if packageDetail.Weight > float64(maxCarriableWeight) {
+			unshippable = append(unshippable, packageDetail)
+		} else {
+			shippable = append(shippable, packageDetail)
+		}
+	}
+	return shippable, unshippable
+}
 
-	current := Subset{}
-	subsets := make([]Subset, 0)
+// Function to get all shipment subsets, each one packed for the vehicle which takes it
+// The vehicle which comes back first and can carry the lightest package left takes the next shipment,
+// the lower number wins on equal times. The packing objective of the plan picks the packages of each shipment
+// The travel times are rounded with the time model before they're added up, and each trip is planned inside a shift
+// of the schedule, the vehicle which can start first takes the shipment
+// The packages of the highest priority left are shipped first, see priorityShipment
+func getShipmentSubsets(packages []PackageDetail, fleet []Vehicle, plan planSettings) ([]Subset, error) {
+	result := make([]Subset, 0)
+	vehicles := make([]vehicleAvailability, len(fleet))
+	for i := range vehicles {
+		vehicles[i].Vehicle = i + 1
+	}
 
-	getSubsets(packages, maxSize, 0, current, maxCarriableWeight, &subsets)
+	for len(packages) > 0 {
+		next, nextStart := -1, 0.0
+		for i, v := range vehicles {
+			if float64(fleet[i].MaxCarriableWeight) < packages[0].Weight {
+				continue
+			}
+			start, _ := plan.Schedule.departure(i, v.AvailableAt, 0)
+			if next == -1 || start < nextStart {
+				next, nextStart = i, start
+			}
+		}
+		if next == -1 {
+			// No vehicle can carry the packages left, they are rejected before planning
+			break
+		}
+		vehicle := fleet[next]
 
-	bestSubset := getBestSubset(subsets)
+		var bestSubset Subset
+		isUrgent := false
+		if plan.PrioritizeDeadlines {
+			bestSubset, isUrgent = deadlineShipment(packages, vehicle, nextStart, laterStart(vehicles, next, plan.Schedule), plan)
+		}
+		if !isUrgent {
+			bestSubset, isUrgent = priorityShipment(packages, vehicle, plan)
+		}
+		if !isUrgent {
+			bestSubset = plan.objective().NextShipment(packages, vehicle, plan)
+		}
+
+		bestSubset, tripTime := plan.tripTime(bestSubset, vehicle)
+		departureTime, ok := plan.Schedule.departure(next, vehicles[next].AvailableAt, tripTime)
+		if !ok {
+			return nil, fmt.Errorf("Validate schedule error: A trip of %s hours of vehicle %s is longer than its shifts", formatMeasure(plan.TimeModel.round(tripTime)), vehicle.Id)
+		}
 
-	remainedPackages := removePackage(packages, bestSubset.PackageDetailIndices)
-	*result = append(*result, bestSubset)
-	if len(remainedPackages) > 0 {
-		getShipmentSubsets(remainedPackages, maxCarriableWeight, result)
+		vehicles[next].Trips++
+		bestSubset.Vehicle = vehicles[next].Vehicle
+		bestSubset.Trip = vehicles[next].Trips
+		bestSubset.DepartureTime = departureTime
+		vehicles[next].AvailableAt = departureTime + tripTime
+		bestSubset.ReturnTime = vehicles[next].AvailableAt
+
+		packages = removePackage(packages, bestSubset.PackageDetailIndices)
+		result = append(result, bestSubset)
 	}
+	return result, nil
+}
+
+// Function to calculate the time of the round trip of a shipment, the subset gets its route in the routing mode
+func (plan planSettings) tripTime(subset Subset, vehicle Vehicle) (Subset, float64) {
+	if plan.Routing {
+		subset = routeSubset(subset)
+		return subset, plan.TimeModel.step(subset.RouteLength / float64(vehicle.MaxSpeed))
+	}
+	return subset, plan.TimeModel.step(subset.MaxDistance/float64(vehicle.MaxSpeed)) * 2
 }
 
 // Function to find the max possible subset size
-// The given packages array should be sorted based on the weight
+// The given packages array should be sorted based on the weight, the weights are summed in milli units so decimals add up exactly
 func findMaxSubsetSize(sortedPackages []PackageDetail, maxCarriableWeight int) int {
 	sum := 0
 	subsetSize := 0
 	for i := 0; i < len(sortedPackages); i++ {
-		sum += sortedPackages[i].Weight
-		if sum <= maxCarriableWeight {
+		sum += milliUnits(sortedPackages[i].Weight)
+		if sum <= maxCarriableWeight*measureScale {
 			subsetSize++
 		} else {
 			break
@@ -85,61 +325,16 @@ func findMaxSubsetSize(sortedPackages []PackageDetail, maxCarriableWeight int) i
 	return subsetSize
 }
 
-// Function to get all subsets with specific size of packages
-// Note: the sum of packages weight in each subset should not exceed maxCarriableWeight
-func getSubsets(packages []PackageDetail, subsetSize int, index int, currentSubset Subset, maxCarriableWeight int, subsets *[]Subset) {
-	// successful stop clause
-	if len(currentSubset.PackageDetailIndices) == subsetSize {
-		*subsets = append(*subsets, currentSubset)
-		return
-	}
-
-	// unseccessful stop clause
-	if index == len(packages) {
-		return
-	}
-
-	currentPackage := packages[index]
-	nextSubnet := currentSubset
-
-	if currentSubset.TotalWeight+currentPackage.Weight <= maxCarriableWeight {
-		currentSubset.PackageDetailIndices = append(currentSubset.PackageDetailIndices, index)
-		currentSubset.PackageDetails = append(currentSubset.PackageDetails, packages[index])
-		currentSubset.TotalWeight += currentPackage.Weight
-		if currentSubset.MaxDistance < currentPackage.Distance {
-			currentSubset.MaxDistance = currentPackage.Distance
-		}
-		// subset with x
-		getSubsets(packages, subsetSize, index+1, currentSubset, maxCarriableWeight, subsets)
-	}
-	// subset without x
-	getSubsets(packages, subsetSize, index+1, nextSubnet, maxCarriableWeight, subsets)
-}
-
-// Function to get the subset with max weight/distance between found subsets
-func getBestSubset(subsets []Subset) Subset {
-	if len(subsets) > 1 {
-		sort.Slice(subsets, func(i, j int) bool {
-			return (subsets[i].TotalWeight > subsets[j].TotalWeight ||
-				(subsets[i].TotalWeight == subsets[j].TotalWeight && subsets[i].MaxDistance < subsets[j].MaxDistance))
-		})
-	}
-	return subsets[0]
-}
-
 // Function to remove packages from array by index and return a new array
 func removePackage(packages []PackageDetail, toRemoveIndexes []int) []PackageDetail {
-	ret := make([]PackageDetail, 0)
+	isRemoved := make([]bool, len(packages))
+	for _, i := range toRemoveIndexes {
+		isRemoved[i] = true
+	}
 
+	ret := make([]PackageDetail, 0, len(packages)-len(toRemoveIndexes))
 	for i := 0; i < len(packages); i++ {
-		isRemoved := false
-		for _, j := range toRemoveIndexes {
-			if i == j {
-				isRemoved = true
-				break
-			}
-		}
-		if !isRemoved {
+		if !isRemoved[i] {
 			ret = append(ret, packages[i])
 		}
 	}
@@ -147,9 +342,31 @@ func removePackage(packages []PackageDetail, toRemoveIndexes []int) []PackageDet
 }
 
 // Function to validate extra details based on the problem explenation
+// The line is either "numberOfVehicles maxSpeed maxCarriableWeight", one "id:maxSpeed:maxCarriableWeight"
+// token per vehicle or "@path" of a fleet file
 func validateExtraDetails(extraDetails [][]string) (ExtraDetails, error) {
 	var extraDetail ExtraDetails
-	if len(extraDetails) != 1 || len(extraDetails[0]) != 3 {
+	if len(extraDetails) != 1 || len(extraDetails[0]) == 0 || extraDetails[0][0] == "" {
+		return extraDetail, fmt.Errorf("%s", "Validate extra details error: Wrong number of inputs")
+	}
+
+	if len(extraDetails[0]) == 1 && strings.HasPrefix(extraDetails[0][0], "@") {
+		vehicles, err := LoadFleet(strings.TrimPrefix(extraDetails[0][0], "@"))
+		if err != nil {
+			return extraDetail, err
+		}
+		return ExtraDetails{Vehicles: vehicles}, nil
+	}
+
+	if strings.Contains(extraDetails[0][0], ":") {
+		vehicles, err := parseFleetTokens(extraDetails[0])
+		if err != nil {
+			return extraDetail, err
+		}
+		return ExtraDetails{Vehicles: vehicles}, nil
+	}
+
+	if len(extraDetails[0]) != 3 {
 		return extraDetail, fmt.Errorf("%s", "Validate extra details error: Wrong number of inputs")
 	}
 
@@ -175,40 +392,76 @@ func validateExtraDetails(extraDetails [][]string) (ExtraDetails, error) {
 	}, nil
 }
 
+// Function to check the extra details lines without solving the problem
+func checkExtraDetails(extraDetails [][]string) error {
+	validatedExtraDetails, err := validateExtraDetails(extraDetails)
+	if err != nil {
+		return err
+	}
+	return validateFleet(validatedExtraDetails)
+}
+
 // Function to calculate delivery time and create a shipmentDetail object for each package
-func calculateShipmentDetails(firstInputLine FirstLineInput, shipmentSubsets []Subset, extraDetails ExtraDetails) []ShipmentDetail {
+func calculateShipmentDetails(firstInputLine FirstLineInput, shipmentSubsets []Subset, fleet []Vehicle, options SolverOptions) []ShipmentDetail {
 
+	timeModel := options.activeTimeModel()
 	result := make([]ShipmentDetail, firstInputLine.NumberOfPackages)
-	vehiclesMaxDeliveryTime := make([]float64, extraDetails.NumberOfVehicles)
-
-	for i := 0; i < len(shipmentSubsets); i++ {
-		sort.Slice(vehiclesMaxDeliveryTime, func(i, j int) bool {
-			return vehiclesMaxDeliveryTime[i] < vehiclesMaxDeliveryTime[j]
-		})
-
-		waitingTime := vehiclesMaxDeliveryTime[0]
-		for _, d := range shipmentSubsets[i].PackageDetails {
-			// Using the first problem
-			deliveryCost := calculateTotalCost(firstInputLine.BaseCost, d)
-
-			baseTime := float64(d.Distance) / float64(extraDetails.MaxSpeed)
-			deliveryTime := roundoff(baseTime, 2) + waitingTime
+	for _, subset := range shipmentSubsets {
+		vehicle := fleet[subset.Vehicle-1]
+		for _, d := range subset.PackageDetails {
+			deliveryTime := packageDeliveryTime(subset, d, vehicle, timeModel)
 
 			result[d.Index] = ShipmentDetail{
-				Title:        d.Title,
-				Discount:     deliveryCost.Discount,
-				TotalCost:    deliveryCost.TotalCost,
-				DeliveryTime: deliveryTime,
+				// Using the first problem
+				CalculationOutput: calculateTotalCost(firstInputLine, d, options),
+				DeliveryTime:      timeModel.present(deliveryTime),
+				DeliveryAt:        timeModel.clock(deliveryTime),
+				Vehicle:           subset.Vehicle,
+				VehicleId:         vehicle.Id,
+				Trip:              subset.Trip,
 			}
 		}
-		maxDeliveryTime := float64(shipmentSubsets[i].MaxDistance) / float64(extraDetails.MaxSpeed)
-		vehiclesMaxDeliveryTime[0] += (roundoff(maxDeliveryTime, 2) * 2)
 	}
 	return result
 }
 
-// Function to roundoff decimal points without rounding or flooring
-func roundoff(num float64, floating_point float64) float64 {
-	d := math.Pow(10, floating_point)
-	return math.Floor(num*d) / d
+// Function to calculate the time a package of a shipment is delivered, in hours since the dispatch start
+func packageDeliveryTime(subset Subset, packageDetail PackageDetail, vehicle Vehicle, timeModel TimeModel) float64 {
+	return subset.DepartureTime + timeModel.step(subset.stopDistance(packageDetail)/float64(vehicle.MaxSpeed))
+}
+
+// Function to return the distance driven to a package, its route distance in the routing mode
+func (subset Subset) stopDistance(packageDetail PackageDetail) float64 {
+	if distance, ok := subset.StopDistances[packageDetail.Index]; ok {
+		return distance
+	}
+	return packageDetail.Distance
+}
+
+// Function to create the dispatch plan, the packages of a shipment are ordered by their delivery time
+func calculateDispatches(shipmentSubsets []Subset, fleet []Vehicle, timeModel TimeModel) []Dispatch {
+	dispatches := make([]Dispatch, 0, len(shipmentSubsets))
+	for _, subset := range shipmentSubsets {
+		packages := append([]PackageDetail{}, subset.PackageDetails...)
+		sort.SliceStable(packages, func(i, j int) bool {
+			return subset.stopDistance(packages[i]) < subset.stopDistance(packages[j])
+		})
+
+		titles := make([]string, len(packages))
+		for i, p := range packages {
+			titles[i] = p.Title
+		}
+		dispatches = append(dispatches, Dispatch{
+			Vehicle:       subset.Vehicle,
+			VehicleId:     fleet[subset.Vehicle-1].Id,
+			Trip:          subset.Trip,
+			DepartureTime: timeModel.present(subset.DepartureTime),
+			ReturnTime:    timeModel.present(subset.ReturnTime),
+			DepartureAt:   timeModel.clock(subset.DepartureTime),
+			ReturnAt:      timeModel.clock(subset.ReturnTime),
+			Packages:      titles,
+			RouteLength:   subset.RouteLength,
+		})
+	}
+	return dispatches
 }
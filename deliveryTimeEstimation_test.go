@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -8,17 +10,17 @@ import (
 
 func TestCalculateDeliveryTime(t *testing.T) {
 	firstLineInput := FirstLineInput{
-		BaseCost:         100,
+		BaseCost:         moneyOf(100),
 		NumberOfPackages: 5,
 	}
 
 	extraDetails := [][]string{{"2", "70", "200"}}
 
 	t.Run("return error if extraDetails are empty", func(t *testing.T) {
-		outputs, err := CalculateDeliveryTime(firstLineInput, []PackageDetail{}, nil)
+		result, err := CalculateDeliveryTime(firstLineInput, []PackageDetail{}, nil, SolverOptions{Offers: defaultOfferCatalog()})
 
 		assert.Error(t, err, "Validate extra details error: Wrong number of inputs")
-		assert.Equal(t, []string(nil), outputs)
+		assert.Equal(t, Result{}, result)
 	})
 
 	t.Run("return correct output", func(t *testing.T) {
@@ -59,9 +61,164 @@ func TestCalculateDeliveryTime(t *testing.T) {
 				OfferIds: []string{"NA"},
 			},
 		}
-		outputs, err := CalculateDeliveryTime(firstLineInput, packageDetails, extraDetails)
+		result, err := CalculateDeliveryTime(firstLineInput, packageDetails, extraDetails, SolverOptions{Offers: defaultOfferCatalog()})
 
 		assert.NoError(t, err)
-		assert.Equal(t, []string{"PKG1 0 750 3.98", "PKG2 0 1475 1.78", "PKG3 0 2350 1.42", "PKG4 105 1395 0.85", "PKG5 0 2125 4.19"}, outputs)
+		assert.Equal(t, []ShipmentDetail{
+			{CalculationOutput: CalculationOutput{Title: "PKG1", Discount: moneyOf(0), TotalCost: moneyOf(750), AppliedOfferIds: []string{}}, DeliveryTime: 3.98, Vehicle: 1, VehicleId: "1", Trip: 2},
+			{CalculationOutput: CalculationOutput{Title: "PKG2", Discount: moneyOf(0), TotalCost: moneyOf(1475), AppliedOfferIds: []string{}}, DeliveryTime: 1.78, Vehicle: 1, VehicleId: "1", Trip: 1},
+			{CalculationOutput: CalculationOutput{Title: "PKG3", Discount: moneyOf(0), TotalCost: moneyOf(2350), AppliedOfferIds: []string{}}, DeliveryTime: 1.42, Vehicle: 2, VehicleId: "2", Trip: 1},
+			{CalculationOutput: CalculationOutput{Title: "PKG4", Discount: moneyOf(105), TotalCost: moneyOf(1395), AppliedOfferIds: []string{"OFR002"}, AppliedOffers: []AppliedOffer{{Id: "OFR002", Type: OfferPercent, Discount: moneyOf(105)}}}, DeliveryTime: 0.85, Vehicle: 1, VehicleId: "1", Trip: 1},
+			{CalculationOutput: CalculationOutput{Title: "PKG5", Discount: moneyOf(0), TotalCost: moneyOf(2125), AppliedOfferIds: []string{}}, DeliveryTime: 4.19, Vehicle: 2, VehicleId: "2", Trip: 2},
+		}, result.Shipments)
+	})
+
+	t.Run("assign shipments to vehicles which can carry them", func(t *testing.T) {
+		packageDetails := []PackageDetail{
+			{Index: 0, Title: "PKG1", Weight: 50, Distance: 30, OfferIds: []string{"NA"}},
+			{Index: 1, Title: "PKG2", Weight: 75, Distance: 125, OfferIds: []string{"NA"}},
+			{Index: 2, Title: "PKG3", Weight: 175, Distance: 100, OfferIds: []string{"NA"}},
+			{Index: 3, Title: "PKG4", Weight: 110, Distance: 60, OfferIds: []string{"NA"}},
+			{Index: 4, Title: "PKG5", Weight: 155, Distance: 95, OfferIds: []string{"NA"}},
+		}
+		fleet := [][]string{{"BIKE:30:60", "VAN:70:200"}}
+		result, err := CalculateDeliveryTime(firstLineInput, packageDetails, fleet, SolverOptions{Offers: defaultOfferCatalog()})
+
+		assert.NoError(t, err)
+		summary := []string{}
+		for _, o := range result.Shipments {
+			summary = append(summary, fmt.Sprintf("%s %.2f %s %d", o.Title, o.DeliveryTime, o.VehicleId, o.Trip))
+		}
+		assert.Equal(t, []string{"PKG1 1.00 BIKE 1", "PKG2 1.78 VAN 1", "PKG3 4.98 VAN 2", "PKG4 0.85 VAN 1", "PKG5 7.75 VAN 3"}, summary)
+	})
+	t.Run("return the dispatch plan in departure order", func(t *testing.T) {
+		packageDetails := []PackageDetail{
+			{Index: 0, Title: "PKG1", Weight: 50, Distance: 30, OfferIds: []string{"NA"}},
+			{Index: 1, Title: "PKG2", Weight: 75, Distance: 125, OfferIds: []string{"NA"}},
+			{Index: 2, Title: "PKG3", Weight: 175, Distance: 100, OfferIds: []string{"NA"}},
+			{Index: 3, Title: "PKG4", Weight: 110, Distance: 60, OfferIds: []string{"NA"}},
+			{Index: 4, Title: "PKG5", Weight: 155, Distance: 95, OfferIds: []string{"NA"}},
+		}
+		result, err := CalculateDeliveryTime(firstLineInput, packageDetails, extraDetails, SolverOptions{Offers: defaultOfferCatalog()})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []Dispatch{
+			{Vehicle: 1, VehicleId: "1", Trip: 1, DepartureTime: 0, ReturnTime: 3.56, Packages: []string{"PKG4", "PKG2"}},
+			{Vehicle: 2, VehicleId: "2", Trip: 1, DepartureTime: 0, ReturnTime: 2.84, Packages: []string{"PKG3"}},
+			{Vehicle: 2, VehicleId: "2", Trip: 2, DepartureTime: 2.84, ReturnTime: 5.54, Packages: []string{"PKG5"}},
+			{Vehicle: 1, VehicleId: "1", Trip: 2, DepartureTime: 3.56, ReturnTime: 4.4, Packages: []string{"PKG1"}},
+		}, result.Dispatches)
+	})
+	t.Run("round the times only in the output", func(t *testing.T) {
+		packageDetails := []PackageDetail{
+			{Index: 0, Title: "PKG1", Weight: 50, Distance: 30, OfferIds: []string{"NA"}},
+			{Index: 1, Title: "PKG2", Weight: 75, Distance: 125, OfferIds: []string{"NA"}},
+			{Index: 2, Title: "PKG3", Weight: 175, Distance: 100, OfferIds: []string{"NA"}},
+			{Index: 3, Title: "PKG4", Weight: 110, Distance: 60, OfferIds: []string{"NA"}},
+			{Index: 4, Title: "PKG5", Weight: 155, Distance: 95, OfferIds: []string{"NA"}},
+		}
+		options := SolverOptions{Offers: defaultOfferCatalog()}
+		assert.NoError(t, setTimeModel(&options, 2, "", true, "", ""))
+		result, err := CalculateDeliveryTime(firstLineInput, packageDetails, extraDetails, options)
+
+		// The return times are not cut before the next trip, PKG5 leaves at 2.857... instead of 2.84
+		assert.NoError(t, err)
+		times := []float64{}
+		for _, o := range result.Shipments {
+			times = append(times, o.DeliveryTime)
+		}
+		assert.Equal(t, []float64{4, 1.78, 1.42, 0.85, 4.21}, times)
+	})
+	t.Run("return the times in minutes and wall-clock time", func(t *testing.T) {
+		packageDetails := []PackageDetail{
+			{Index: 0, Title: "PKG1", Weight: 50, Distance: 30, OfferIds: []string{"NA"}},
+			{Index: 1, Title: "PKG2", Weight: 75, Distance: 125, OfferIds: []string{"NA"}},
+		}
+		firstLineInput := FirstLineInput{BaseCost: moneyOf(100), NumberOfPackages: 2}
+		options := SolverOptions{Offers: defaultOfferCatalog()}
+
+		assert.NoError(t, setTimeModel(&options, 2, "", false, "minutes", ""))
+		result, err := CalculateDeliveryTime(firstLineInput, packageDetails, extraDetails, options)
+		assert.NoError(t, err)
+		assert.Equal(t, TimeMinutes, result.TimeUnit)
+		assert.Equal(t, []float64{25.2, 106.8}, []float64{result.Shipments[0].DeliveryTime, result.Shipments[1].DeliveryTime})
+
+		assert.NoError(t, setTimeModel(&options, 2, "", false, "clock", "2024-03-15T08:00:00Z"))
+		result, err = CalculateDeliveryTime(firstLineInput, packageDetails, extraDetails, options)
+		assert.NoError(t, err)
+		assert.Equal(t, "2024-03-15T08:25:12Z", result.Shipments[0].DeliveryAt)
+		assert.Equal(t, "2024-03-15T08:00:00Z", result.Dispatches[0].DepartureAt)
+		assert.Equal(t, "2024-03-15T11:33:36Z", result.Dispatches[0].ReturnAt)
+	})
+	t.Run("return error listing every package heavier than any vehicle can carry", func(t *testing.T) {
+		packageDetails := []PackageDetail{
+			{Index: 0, Title: "PKG1", Weight: 50, Distance: 30, OfferIds: []string{"NA"}},
+			{Index: 1, Title: "PKG2", Weight: 250, Distance: 125, OfferIds: []string{"NA"}},
+			{Index: 2, Title: "PKG3", Weight: 210, Distance: 100, OfferIds: []string{"NA"}},
+		}
+		_, err := CalculateDeliveryTime(FirstLineInput{BaseCost: moneyOf(100), NumberOfPackages: 3}, packageDetails, extraDetails, SolverOptions{Offers: defaultOfferCatalog()})
+
+		var unshippableError *UnshippableError
+		assert.True(t, errors.As(err, &unshippableError))
+		assert.Equal(t, []string{"PKG2", "PKG3"}, []string{unshippableError.Packages[0].Title, unshippableError.Packages[1].Title})
+		assert.EqualError(t, err, "Validate shipment error: 2 package(s) heavier than the max carriable weight 200: PKG2 (250), PKG3 (210)")
+	})
+	t.Run("mark packages heavier than any vehicle can carry as undeliverable when skipping them", func(t *testing.T) {
+		packageDetails := []PackageDetail{
+			{Index: 0, Title: "PKG1", Weight: 50, Distance: 30, OfferIds: []string{"NA"}},
+			{Index: 1, Title: "PKG2", Weight: 250, Distance: 125, OfferIds: []string{"NA"}},
+		}
+		result, err := CalculateDeliveryTime(FirstLineInput{BaseCost: moneyOf(100), NumberOfPackages: 2}, packageDetails, extraDetails,
+			SolverOptions{Offers: defaultOfferCatalog(), SkipUnshippable: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []ShipmentDetail{
+			{CalculationOutput: CalculationOutput{Title: "PKG1", Discount: moneyOf(0), TotalCost: moneyOf(750), AppliedOfferIds: []string{}}, DeliveryTime: 0.42, Vehicle: 1, VehicleId: "1", Trip: 1},
+			{CalculationOutput: CalculationOutput{Title: "PKG2", Discount: moneyOf(0), TotalCost: moneyOf(3225), AppliedOfferIds: []string{}}, Undeliverable: true},
+		}, result.Shipments)
+		assert.Equal(t, 1, len(result.Dispatches))
+	})
+	t.Run("return error for a fleet which can't deliver", func(t *testing.T) {
+		for _, fleet := range []struct {
+			input string
+			err   string
+		}{
+			{"0 70 200", "Validate extra details error: Wrong number of vehicles"},
+			{"-1 70 200", "Validate extra details error: Wrong number of vehicles"},
+			{"2 0 200", "Validate extra details error: Wrong max speed"},
+			{"2 70 0", "Validate extra details error: Wrong max carriable weight"},
+			{"BIKE:0:60 VAN:70:200", "Validate extra details error: Wrong max speed of vehicle BIKE"},
+			{"BIKE:30:0", "Validate extra details error: Wrong max carriable weight of vehicle BIKE"},
+		} {
+			_, err := CalculateDeliveryTime(firstLineInput, []PackageDetail{}, [][]string{splitInputLine(fleet.input)}, SolverOptions{})
+
+			assert.EqualError(t, err, fleet.err, fleet.input)
+		}
+	})
+}
+
+func TestValidateExtraDetails(t *testing.T) {
+	t.Run("return the shared vehicle settings", func(t *testing.T) {
+		extraDetails, err := validateExtraDetails([][]string{{"2", "70", "200"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, ExtraDetails{NumberOfVehicles: 2, MaxSpeed: 70, MaxCarriableWeight: 200}, extraDetails)
+	})
+	t.Run("return the inline fleet", func(t *testing.T) {
+		extraDetails, err := validateExtraDetails([][]string{{"VAN1:70:200", "BIKE1:30:20"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(extraDetails.Vehicles))
+	})
+	t.Run("return the fleet of a file", func(t *testing.T) {
+		extraDetails, err := validateExtraDetails([][]string{{"@fleet.example.yaml"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "TRUCK1", extraDetails.Vehicles[1].Id)
+	})
+	t.Run("return error for wrong number of inputs", func(t *testing.T) {
+		_, err := validateExtraDetails([][]string{{"2", "70"}})
+
+		assert.EqualError(t, err, "Validate extra details error: Wrong number of inputs")
 	})
 }
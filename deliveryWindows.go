@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// The promised delivery window of a package, in hours since the dispatch start
+type timeWindow struct {
+	NotBefore    float64
+	DueBy        float64
+	HasNotBefore bool
+	HasDueBy     bool
+}
+
+// The check of the delivery windows of the planned packages
+type SlaReport struct {
+	Checked int          `json:"checked"` // The number of packages with a delivery window
+	OnTime  int          `json:"onTime"`
+	Late    []SlaPackage `json:"late,omitempty"`
+	Early   []SlaPackage `json:"early,omitempty"`
+}
+
+// A package delivered outside of its window
+type SlaPackage struct {
+	Title string `json:"title"`
+	// How much later than its due time or earlier than its window start the package is delivered,
+	// in minutes for the minutes time unit and in hours otherwise
+	Deviation float64 `json:"deviation"`
+	// The package is heavier than any vehicle can carry, it's late with no deviation
+	Undeliverable bool `json:"undeliverable,omitempty"`
+}
+
+// The tolerance of the window checks, the times are rounded and the due times have at most 3 decimals
+const windowEpsilon = 1e-9
+
+// Function to check a time of a package window
+func checkPackageTime(value string) error {
+	_, _, err := parsePackageTime(value)
+	return err
+}
+
+// Function to parse a time of a package window, either hours since the dispatch start or a RFC 3339 timestamp
+// The hours are returned for the first form, the timestamp for the second one
+func parsePackageTime(value string) (float64, time.Time, error) {
+	if hours, err := parseMeasure(value); err == nil && hours >= 0 {
+		return hours, time.Time{}, nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("'%s' should be hours like 4.5 or a time like 2006-01-02T15:04:05Z", value)
+	}
+	return 0, timestamp, nil
+}
+
+// Function to resolve the delivery windows of the packages by their index, timestamps are converted with the dispatch start
+func packageWindows(packages []PackageDetail, start time.Time) (map[int]timeWindow, error) {
+	windows := map[int]timeWindow{}
+	for _, p := range packages {
+		if p.NotBefore == "" && p.DueBy == "" {
+			continue
+		}
+		window := timeWindow{}
+		var err error
+		if p.NotBefore != "" {
+			window.HasNotBefore = true
+			if window.NotBefore, err = windowHours(p.NotBefore, start); err != nil {
+				return nil, fmt.Errorf("Validate package error: The window start of package %s %w", p.Title, err)
+			}
+		}
+		if p.DueBy != "" {
+			window.HasDueBy = true
+			if window.DueBy, err = windowHours(p.DueBy, start); err != nil {
+				return nil, fmt.Errorf("Validate package error: The due time of package %s %w", p.Title, err)
+			}
+		}
+		if window.HasNotBefore && window.HasDueBy && window.DueBy < window.NotBefore {
+			return nil, fmt.Errorf("Validate package error: The due time of package %s is before its window start", p.Title)
+		}
+		windows[p.Index] = window
+	}
+	return windows, nil
+}
+
+// Function to convert a time of a package window to hours since the dispatch start
+func windowHours(value string, start time.Time) (float64, error) {
+	hours, timestamp, err := parsePackageTime(value)
+	if err != nil {
+		return 0, err
+	}
+	if timestamp.IsZero() {
+		return hours, nil
+	}
+	if start.IsZero() {
+		return 0, fmt.Errorf("'%s' needs a dispatch start", value)
+	}
+	return timestamp.Sub(start).Hours(), nil
+}
+
+// Function to pick the next shipment when some packages would miss their due time if they wait for a later trip
+// A package is at risk if it's late when it leaves with the next vehicle after this one, or with this vehicle after
+// a round trip to it. The packages at risk are taken first, the one with the least slack first, then the rest of
+// the capacity is filled with the lightest packages. It returns false if no package is at risk
+// In the routing mode the travel is the straight line from the depot to the package location, like the routes
+// The given packages array should be sorted based on the weight
+func deadlineShipment(sortedPackages []PackageDetail, vehicle Vehicle, start float64, laterStart float64, plan planSettings) (Subset, bool) {
+	slack := map[int]float64{}
+	atRisk := []int{}
+	for i, p := range sortedPackages {
+		window, ok := plan.Windows[p.Index]
+		if !ok || !window.HasDueBy {
+			continue
+		}
+		travel := plan.TimeModel.step(plan.depotDistance(p) / float64(vehicle.MaxSpeed))
+		if math.Min(laterStart, start+2*travel)+travel > window.DueBy+windowEpsilon {
+			atRisk = append(atRisk, i)
+			slack[i] = window.DueBy - travel
+		}
+	}
+	if len(atRisk) == 0 {
+		return Subset{}, false
+	}
+	sort.SliceStable(atRisk, func(i, j int) bool {
+		return slack[atRisk[i]] < slack[atRisk[j]]
+	})
+
+	maxWeight := vehicle.MaxCarriableWeight * measureScale
+	totalWeight := 0
+	isChosen := make([]bool, len(sortedPackages))
+	for _, i := range atRisk {
+		if weight := milliUnits(sortedPackages[i].Weight); totalWeight+weight <= maxWeight {
+			isChosen[i] = true
+			totalWeight += weight
+		}
+	}
+	subset := fillShipment(sortedPackages, isChosen, totalWeight, vehicle.MaxCarriableWeight)
+	return subset, len(subset.PackageDetailIndices) > 0
+}
+
+// Function to find the earliest time another vehicle can start a trip, infinity if there is no other vehicle
+func laterStart(vehicles []vehicleAvailability, current int, schedule Schedule) float64 {
+	earliest := math.Inf(1)
+	for i, v := range vehicles {
+		if i == current {
+			continue
+		}
+		if start, _ := schedule.departure(i, v.AvailableAt, 0); start < earliest {
+			earliest = start
+		}
+	}
+	return earliest
+}
+
+// Function to check the delivery times of the planned packages against their windows, nil if no package has one
+// The packages which no vehicle can carry are late. The late and early packages are in input order
+func slaReport(shipmentSubsets []Subset, fleet []Vehicle, unshippable []PackageDetail, plan planSettings) *SlaReport {
+	if len(plan.Windows) == 0 {
+		return nil
+	}
+
+	deliveryTimes := map[int]float64{}
+	titles := map[int]string{}
+	for _, subset := range shipmentSubsets {
+		for _, p := range subset.PackageDetails {
+			deliveryTimes[p.Index] = packageDeliveryTime(subset, p, fleet[subset.Vehicle-1], plan.TimeModel)
+			titles[p.Index] = p.Title
+		}
+	}
+	for _, p := range unshippable {
+		titles[p.Index] = p.Title
+	}
+	indices := []int{}
+	for index := range plan.Windows {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+
+	report := SlaReport{Checked: len(indices)}
+	for _, index := range indices {
+		window := plan.Windows[index]
+		deliveryTime, isPlanned := deliveryTimes[index]
+		switch {
+		case !isPlanned:
+			report.Late = append(report.Late, SlaPackage{Title: titles[index], Undeliverable: true})
+		case window.HasDueBy && deliveryTime > window.DueBy+windowEpsilon:
+			report.Late = append(report.Late, SlaPackage{Title: titles[index], Deviation: plan.TimeModel.present(deliveryTime - window.DueBy)})
+		case window.HasNotBefore && deliveryTime < window.NotBefore-windowEpsilon:
+			report.Early = append(report.Early, SlaPackage{Title: titles[index], Deviation: plan.TimeModel.present(window.NotBefore - deliveryTime)})
+		default:
+			report.OnTime++
+		}
+	}
+	return &report
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePackageTime(t *testing.T) {
+	t.Run("return hours since the dispatch start", func(t *testing.T) {
+		hours, timestamp, err := parsePackageTime("4.5")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4.5, hours)
+		assert.True(t, timestamp.IsZero())
+	})
+	t.Run("return a wall-clock time", func(t *testing.T) {
+		_, timestamp, err := parsePackageTime("2024-03-15T12:00:00Z")
+
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC), timestamp)
+	})
+	t.Run("return error for other inputs", func(t *testing.T) {
+		for _, input := range []string{"-1", "4h", "12:00", "2024-03-15"} {
+			assert.EqualError(t, checkPackageTime(input), "'"+input+"' should be hours like 4.5 or a time like 2006-01-02T15:04:05Z", input)
+		}
+	})
+}
+
+func TestPackageWindows(t *testing.T) {
+	start := time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC)
+
+	t.Run("convert the timestamps with the dispatch start", func(t *testing.T) {
+		windows, err := packageWindows([]PackageDetail{
+			{Index: 0, Title: "PKG1"},
+			{Index: 1, Title: "PKG2", NotBefore: "1", DueBy: "2024-03-15T10:30:00Z"},
+		}, start)
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[int]timeWindow{1: {NotBefore: 1, DueBy: 2.5, HasNotBefore: true, HasDueBy: true}}, windows)
+	})
+	t.Run("return error for a timestamp without dispatch start", func(t *testing.T) {
+		_, err := packageWindows([]PackageDetail{{Title: "PKG1", DueBy: "2024-03-15T10:30:00Z"}}, time.Time{})
+
+		assert.EqualError(t, err, "Validate package error: The due time of package PKG1 '2024-03-15T10:30:00Z' needs a dispatch start")
+	})
+	t.Run("return error for a due time before the window start", func(t *testing.T) {
+		_, err := packageWindows([]PackageDetail{{Title: "PKG1", NotBefore: "3", DueBy: "2"}}, start)
+
+		assert.EqualError(t, err, "Validate package error: The due time of package PKG1 is before its window start")
+	})
+}
+
+func TestDeliveryWindows(t *testing.T) {
+	firstLineInput := FirstLineInput{BaseCost: moneyOf(100), NumberOfPackages: 5}
+	packages := func() []PackageDetail {
+		return []PackageDetail{
+			{Index: 0, Title: "PKG1", Weight: 50, Distance: 30, OfferIds: []string{"NA"}, DueBy: "1"},
+			{Index: 1, Title: "PKG2", Weight: 75, Distance: 125, OfferIds: []string{"NA"}},
+			{Index: 2, Title: "PKG3", Weight: 175, Distance: 100, OfferIds: []string{"NA"}, NotBefore: "2"},
+			{Index: 3, Title: "PKG4", Weight: 110, Distance: 60, OfferIds: []string{"NA"}},
+			{Index: 4, Title: "PKG5", Weight: 155, Distance: 95, OfferIds: []string{"NA"}},
+		}
+	}
+	extraDetails := [][]string{{"2", "70", "200"}}
+
+	t.Run("report the late and early packages", func(t *testing.T) {
+		result, err := CalculateDeliveryTime(firstLineInput, packages(), extraDetails, SolverOptions{Offers: defaultOfferCatalog()})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &SlaReport{
+			Checked: 2,
+			Late:    []SlaPackage{{Title: "PKG1", Deviation: 2.98}},
+			Early:   []SlaPackage{{Title: "PKG3", Deviation: 0.58}},
+		}, result.Sla)
+	})
+	t.Run("ship the packages at risk first", func(t *testing.T) {
+		result, err := CalculateDeliveryTime(firstLineInput, packages(), extraDetails, SolverOptions{Offers: defaultOfferCatalog(), PrioritizeDeadlines: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0.42, result.Shipments[0].DeliveryTime)
+		assert.Equal(t, 1, result.Shipments[0].Trip)
+		assert.Equal(t, 2, result.Sla.OnTime)
+		assert.Empty(t, result.Sla.Late)
+	})
+	t.Run("find the packages at risk by their location in the routing mode", func(t *testing.T) {
+		// PKG3 is 10 away by its distance but 140 away by its location, it's late if it waits for the first trip
+		located := []PackageDetail{
+			{Index: 0, Title: "PKG1", Weight: 100, Distance: 35, OfferIds: []string{"NA"}, Location: &Point{X: 35}},
+			{Index: 1, Title: "PKG2", Weight: 100, Distance: 35, OfferIds: []string{"NA"}, Location: &Point{X: 35}},
+			{Index: 2, Title: "PKG3", Weight: 150, Distance: 10, OfferIds: []string{"NA"}, Location: &Point{X: 140}, DueBy: "2.5"},
+		}
+		result, err := CalculateDeliveryTime(FirstLineInput{BaseCost: moneyOf(100), NumberOfPackages: 3}, located, [][]string{{"1", "70", "200"}},
+			SolverOptions{Offers: defaultOfferCatalog(), PrioritizeDeadlines: true, Routing: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Shipments[2].Trip)
+		assert.Equal(t, 2.0, result.Shipments[2].DeliveryTime)
+		assert.Empty(t, result.Sla.Late)
+	})
+	t.Run("report the undeliverable packages as late", func(t *testing.T) {
+		heavy := packages()
+		heavy[1].Weight = 250
+		heavy[1].DueBy = "5"
+		result, err := CalculateDeliveryTime(firstLineInput, heavy, extraDetails, SolverOptions{Offers: defaultOfferCatalog(), SkipUnshippable: true})
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Sla.Late, SlaPackage{Title: "PKG2", Undeliverable: true})
+	})
+	t.Run("have no report without windows", func(t *testing.T) {
+		result, err := CalculateDeliveryTime(firstLineInput, []PackageDetail{{Index: 0, Title: "PKG1", Weight: 50, Distance: 30}}, extraDetails,
+			SolverOptions{Offers: defaultOfferCatalog()})
+
+		assert.NoError(t, err)
+		assert.Nil(t, result.Sla)
+	})
+}
@@ -0,0 +1,66 @@
+package main
+
+import "fmt"
+
+// Function to check the inputs of a problem against the rules of the domain, before a solver runs
+// The base cost and the number of packages are not negative, and each package has a unique id, a weight and a distance more than 0
+// It returns a ValidationError with every issue, the issues of a package have its number
+func validateProblem(firstLineInput FirstLineInput, packageDetails []PackageDetail) error {
+	issues := firstLineIssues(firstLineInput)
+
+	checker := newPackageChecker(nil)
+	for i, packageDetail := range packageDetails {
+		packageIssues := packageIssues(packageDetail)
+		if len(packageIssues) == 0 {
+			packageIssues = checker.check(packageDetail, InputIssue{Package: i + 1}, 0)
+		}
+		for _, issue := range packageIssues {
+			issue.Package = i + 1
+			issues = append(issues, issue)
+		}
+	}
+	return validationError(issues)
+}
+
+// Function to check the base cost and the number of packages, each issue has its field
+func firstLineIssues(firstLineInput FirstLineInput) []InputIssue {
+	issues := []InputIssue{}
+	if firstLineInput.BaseCost < 0 {
+		issues = append(issues, InputIssue{Field: "baseCost",
+			Message: fmt.Sprintf("Validate first line error: The base cost should not be negative, not %s", firstLineInput.BaseCost)})
+	}
+	if firstLineInput.NumberOfPackages < 0 {
+		issues = append(issues, InputIssue{Field: "numberOfPackages",
+			Message: fmt.Sprintf("Validate first line error: The number of packages should not be negative, not %d", firstLineInput.NumberOfPackages)})
+	}
+	return issues
+}
+
+// Function to check the id and the measures of a package, each issue has its field
+func packageIssues(packageDetail PackageDetail) []InputIssue {
+	issues := []InputIssue{}
+	if packageDetail.Title == "" {
+		issues = append(issues, InputIssue{Field: "title", Message: "Validate package error: Missing package id"})
+	}
+	measures := []struct {
+		field string
+		value float64
+	}{{"weight", packageDetail.Weight}, {"distance", packageDetail.Distance}}
+	for _, measure := range measures {
+		if measure.value <= 0 {
+			issues = append(issues, InputIssue{Field: measure.field,
+				Message: fmt.Sprintf("Validate package error: The %s of package %s should be more than 0, not %s", measure.field, packageDetail.Title, formatMeasure(measure.value))})
+		}
+	}
+	return issues
+}
+
+// Function to check an issue of the given field is in the list
+func hasFieldIssue(issues []InputIssue, field string) bool {
+	for _, issue := range issues {
+		if issue.Field == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateProblem(t *testing.T) {
+	t.Run("accept valid packages", func(t *testing.T) {
+		err := validateProblem(FirstLineInput{BaseCost: moneyOf(100), NumberOfPackages: 2}, []PackageDetail{
+			{Title: "PKG1", Weight: 5, Distance: 5},
+			{Title: "PKG2", Index: 1, Weight: 0.5, Distance: 12.25},
+		})
+
+		assert.NoError(t, err)
+	})
+	t.Run("return every issue with the package number and field", func(t *testing.T) {
+		err := validateProblem(FirstLineInput{BaseCost: moneyOf(-1), NumberOfPackages: -3}, []PackageDetail{
+			{Title: "PKG1", Weight: 0, Distance: -5},
+			{Title: "PKG2", Index: 1, Weight: 5, Distance: 5},
+			{Title: "PKG2", Index: 2, Weight: 5, Distance: 5},
+			{Index: 3, Weight: 5, Distance: 5},
+		})
+
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []InputIssue{
+			{Field: "baseCost", Message: "Validate first line error: The base cost should not be negative, not -1"},
+			{Field: "numberOfPackages", Message: "Validate first line error: The number of packages should not be negative, not -3"},
+			{Package: 1, Field: "weight", Message: "Validate package error: The weight of package PKG1 should be more than 0, not 0"},
+			{Package: 1, Field: "distance", Message: "Validate package error: The distance of package PKG1 should be more than 0, not -5"},
+			{Package: 3, Field: "title", Message: "Validate package error: Duplicate package id PKG2, first used on package 2"},
+			{Package: 4, Field: "title", Message: "Validate package error: Missing package id"},
+		}, validationErr.Issues)
+	})
+	t.Run("run before the solvers", func(t *testing.T) {
+		packageDetails := []PackageDetail{{Title: "PKG1", Weight: 5, Distance: 0, OfferIds: []string{}}}
+		options := SolverOptions{Offers: defaultOfferCatalog()}
+
+		_, err := CalculateDeliveryCost(FirstLineInput{BaseCost: moneyOf(100), NumberOfPackages: 1}, packageDetails, nil, options)
+		assert.EqualError(t, err, "package 1: Validate package error: The distance of package PKG1 should be more than 0, not 0")
+
+		_, err = CalculateDeliveryTime(FirstLineInput{BaseCost: moneyOf(100), NumberOfPackages: 1}, packageDetails, [][]string{{"1", "70", "200"}}, options)
+		assert.EqualError(t, err, "package 1: Validate package error: The distance of package PKG1 should be more than 0, not 0")
+	})
+}
+
+func TestValidateFirstLineDomain(t *testing.T) {
+	t.Run("reject a negative number of packages at its column", func(t *testing.T) {
+		_, issues := validateFirstLine(newInputLine(1, "100 -2"))
+
+		assert.Equal(t, []InputIssue{{Line: 1, Column: 5, Field: "numberOfPackages", Message: "Validate first line error: The number of packages should not be negative, not -2"}}, issues)
+	})
+	t.Run("reject a zero weight at its column", func(t *testing.T) {
+		_, issues := validatePackageLine(newInputLine(2, "PKG1 0 5 OFR001"), 0)
+
+		assert.Equal(t, []InputIssue{{Line: 2, Column: 6, Field: "weight", Message: "Validate package error: The weight of package PKG1 should be more than 0, not 0"}}, issues)
+	})
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Vehicle struct {
+	Id                 string `json:"id" yaml:"id"`
+	MaxSpeed           int    `json:"maxSpeed" yaml:"maxSpeed"`
+	MaxCarriableWeight int    `json:"maxCarriableWeight" yaml:"maxCarriableWeight"`
+	// The daily working windows like "08:00-12:00", the trips are planned inside them, optional
+	Shifts []string `json:"shifts,omitempty" yaml:"shifts"`
+}
+
+type fleetFile struct {
+	Vehicles []Vehicle `yaml:"vehicles"`
+}
+
+// Function to return one vehicle definition per vehicle
+// Without a vehicle list, NumberOfVehicles vehicles with the shared speed and weight are used, named by their number
+func (extraDetails ExtraDetails) fleet() []Vehicle {
+	if len(extraDetails.Vehicles) > 0 {
+		return extraDetails.Vehicles
+	}
+
+	vehicles := make([]Vehicle, extraDetails.NumberOfVehicles)
+	for i := range vehicles {
+		vehicles[i] = Vehicle{
+			Id:                 strconv.Itoa(i + 1),
+			MaxSpeed:           extraDetails.MaxSpeed,
+			MaxCarriableWeight: extraDetails.MaxCarriableWeight,
+		}
+	}
+	return vehicles
+}
+
+// Function to parse the inline fleet format, one "id:maxSpeed:maxCarriableWeight" token per vehicle
+func parseFleetTokens(inputTokens []string) ([]Vehicle, error) {
+	vehicles := []Vehicle{}
+	for _, token := range inputTokens {
+		parts := strings.Split(token, ":")
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("Validate extra details error: Wrong vehicle '%s', use id:maxSpeed:maxCarriableWeight", token)
+		}
+
+		maxSpeed, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("Validate extra details error: Wrong max speed of vehicle %s", parts[0])
+		}
+
+		maxCarriableWeight, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("Validate extra details error: Wrong max carriable weight of vehicle %s", parts[0])
+		}
+
+		vehicles = append(vehicles, Vehicle{Id: parts[0], MaxSpeed: maxSpeed, MaxCarriableWeight: maxCarriableWeight})
+	}
+	if err := checkVehicleIds(vehicles); err != nil {
+		return nil, fmt.Errorf("Validate extra details error: %w", err)
+	}
+	return vehicles, nil
+}
+
+// Function to read a fleet file (JSON or YAML) with a "vehicles" list
+func LoadFleet(path string) ([]Vehicle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load fleet error: %w", err)
+	}
+
+	var fleet fleetFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&fleet); err != nil {
+		return nil, fmt.Errorf("load fleet error: %s: %w", path, err)
+	}
+	if len(fleet.Vehicles) == 0 {
+		return nil, fmt.Errorf("load fleet error: %s: No vehicles", path)
+	}
+	for i, vehicle := range fleet.Vehicles {
+		if vehicle.Id == "" {
+			return nil, fmt.Errorf("load fleet error: %s: Missing id of vehicle %d", path, i+1)
+		}
+	}
+	if err := checkVehicleIds(fleet.Vehicles); err != nil {
+		return nil, fmt.Errorf("load fleet error: %s: %w", path, err)
+	}
+	return fleet.Vehicles, nil
+}
+
+// Function to check the fleet can deliver anything: at least one vehicle, each one with a speed and a capacity
+func validateFleet(extraDetails ExtraDetails) error {
+	if len(extraDetails.Vehicles) == 0 {
+		switch {
+		case extraDetails.NumberOfVehicles <= 0:
+			return fmt.Errorf("%s", "Validate extra details error: Wrong number of vehicles")
+		case extraDetails.MaxSpeed <= 0:
+			return fmt.Errorf("%s", "Validate extra details error: Wrong max speed")
+		case extraDetails.MaxCarriableWeight <= 0:
+			return fmt.Errorf("%s", "Validate extra details error: Wrong max carriable weight")
+		}
+		return nil
+	}
+
+	for _, vehicle := range extraDetails.Vehicles {
+		switch {
+		case vehicle.Id == "":
+			return fmt.Errorf("%s", "Validate extra details error: Missing vehicle id")
+		case vehicle.MaxSpeed <= 0:
+			return fmt.Errorf("Validate extra details error: Wrong max speed of vehicle %s", vehicle.Id)
+		case vehicle.MaxCarriableWeight <= 0:
+			return fmt.Errorf("Validate extra details error: Wrong max carriable weight of vehicle %s", vehicle.Id)
+		}
+	}
+	if err := checkVehicleIds(extraDetails.Vehicles); err != nil {
+		return fmt.Errorf("Validate extra details error: %w", err)
+	}
+	return nil
+}
+
+// Function to find the max carriable weight of the biggest vehicle
+func maxFleetCapacity(fleet []Vehicle) int {
+	capacity := 0
+	for _, vehicle := range fleet {
+		if vehicle.MaxCarriableWeight > capacity {
+			capacity = vehicle.MaxCarriableWeight
+		}
+	}
+	return capacity
+}
+
+// Function to reject a fleet with the same vehicle id twice
+func checkVehicleIds(vehicles []Vehicle) error {
+	seenIds := map[string]bool{}
+	for _, vehicle := range vehicles {
+		if seenIds[vehicle.Id] {
+			return fmt.Errorf("Duplicate vehicle id %s", vehicle.Id)
+		}
+		seenIds[vehicle.Id] = true
+	}
+	return nil
+}
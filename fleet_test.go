@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFleetTokens(t *testing.T) {
+	t.Run("return one vehicle per token", func(t *testing.T) {
+		vehicles, err := parseFleetTokens([]string{"VAN1:70:200", "BIKE1:30:20"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []Vehicle{
+			{Id: "VAN1", MaxSpeed: 70, MaxCarriableWeight: 200},
+			{Id: "BIKE1", MaxSpeed: 30, MaxCarriableWeight: 20},
+		}, vehicles)
+	})
+	t.Run("return error for wrong token", func(t *testing.T) {
+		_, err := parseFleetTokens([]string{"VAN1:70"})
+
+		assert.EqualError(t, err, "Validate extra details error: Wrong vehicle 'VAN1:70', use id:maxSpeed:maxCarriableWeight")
+	})
+	t.Run("return error for wrong max speed", func(t *testing.T) {
+		_, err := parseFleetTokens([]string{"VAN1:fast:200"})
+
+		assert.EqualError(t, err, "Validate extra details error: Wrong max speed of vehicle VAN1")
+	})
+	t.Run("return error for duplicate ids", func(t *testing.T) {
+		_, err := parseFleetTokens([]string{"VAN1:70:200", "VAN1:30:20"})
+
+		assert.EqualError(t, err, "Validate extra details error: Duplicate vehicle id VAN1")
+	})
+}
+
+func TestLoadFleet(t *testing.T) {
+	t.Run("load the example fleet", func(t *testing.T) {
+		vehicles, err := LoadFleet("fleet.example.yaml")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(vehicles))
+		assert.Equal(t, Vehicle{Id: "BIKE1", MaxSpeed: 30, MaxCarriableWeight: 20}, vehicles[2])
+	})
+	t.Run("return error for unknown fields", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "fleet.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"vehicles": [{"id": "VAN1", "speed": 70}]}`), 0o644))
+
+		_, err := LoadFleet(path)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestFleet(t *testing.T) {
+	t.Run("return numbered vehicles for the shared speed and weight", func(t *testing.T) {
+		vehicles := ExtraDetails{NumberOfVehicles: 2, MaxSpeed: 70, MaxCarriableWeight: 200}.fleet()
+
+		assert.Equal(t, []Vehicle{
+			{Id: "1", MaxSpeed: 70, MaxCarriableWeight: 200},
+			{Id: "2", MaxSpeed: 70, MaxCarriableWeight: 200},
+		}, vehicles)
+	})
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A problem found in an input, with where it was found
+type InputIssue struct {
+	Line    int    `json:"line,omitempty"`    // The line of a console, batch or manifest input
+	Column  int    `json:"column,omitempty"`  // The column of the token, 1 is the first character of the line
+	Package int    `json:"package,omitempty"` // The package number of an API request or a console prompt
+	Field   string `json:"field,omitempty"`   // The input field, e.g. "weight" or "offers"
+	Message string `json:"message"`
+	// The input is still used, e.g. an unknown offer code only gives no discount
+	Warning bool `json:"warning,omitempty"`
+}
+
+func (issue InputIssue) String() string {
+	message := issue.Message
+	if issue.Warning {
+		message = "warning: " + message
+	}
+	if position := issue.position(); position != "" {
+		return position + ": " + message
+	}
+	return message
+}
+
+// Function to describe where the issue is, like "line 3, column 6", empty if it isn't known
+func (issue InputIssue) position() string {
+	positions := []string{}
+	if issue.Package > 0 {
+		positions = append(positions, fmt.Sprintf("package %d", issue.Package))
+	}
+	if issue.Line > 0 {
+		positions = append(positions, fmt.Sprintf("line %d", issue.Line))
+	}
+	if issue.Column > 0 {
+		positions = append(positions, fmt.Sprintf("column %d", issue.Column))
+	}
+	return strings.Join(positions, ", ")
+}
+
+// All the invalid inputs found at once, one issue per line of the error message
+type ValidationError struct {
+	Issues []InputIssue
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		messages[i] = issue.String()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Function to return the issues which are not warnings as a ValidationError, nil if there is none
+func validationError(issues []InputIssue) error {
+	errorIssues := []InputIssue{}
+	for _, issue := range issues {
+		if !issue.Warning {
+			errorIssues = append(errorIssues, issue)
+		}
+	}
+	if len(errorIssues) == 0 {
+		return nil
+	}
+	return &ValidationError{Issues: errorIssues}
+}
+
+// The tokens of an input line with the column of each one
+type inputLine struct {
+	Number  int // The line number, 0 for a console line
+	Tokens  []string
+	Columns []int // Empty when the tokens don't come from a line, e.g. the columns of a manifest
+}
+
+// Function to split an input line into space separated tokens and keep their columns
+func newInputLine(number int, line string) inputLine {
+	column := len(line) - len(strings.TrimLeft(line, " ")) + 1
+	tokens := splitInputLine(line)
+	columns := make([]int, len(tokens))
+	for i, token := range tokens {
+		columns[i] = column
+		column += len(token) + 1
+	}
+	return inputLine{Number: number, Tokens: tokens, Columns: columns}
+}
+
+// Function to return an issue of a token of the line, the column is 0 for a missing token
+func (line inputLine) issue(token int, field string, message string) InputIssue {
+	issue := InputIssue{Line: line.Number, Field: field, Message: message}
+	if token >= 0 && token < len(line.Columns) {
+		issue.Column = line.Columns[token]
+	}
+	return issue
+}
+
+// Function to validate the first line "baseCost(decimal) numberOfPackages(int)" and return every issue of it
+func validateFirstLine(line inputLine) (FirstLineInput, []InputIssue) {
+	issues := []InputIssue{}
+	tokens := line.Tokens
+	if len(tokens) != 2 {
+		issues = append(issues, line.issue(-1, "", "parse first input line error: Wrong number of inputs"))
+	}
+
+	var firstLineInput FirstLineInput
+	if len(tokens) > 0 {
+		baseCost, err := parseMoney(tokens[0])
+		if err != nil {
+			issues = append(issues, line.issue(0, "baseCost", "parse first input line error: Wrong base cost input"))
+		}
+		firstLineInput.BaseCost = baseCost
+	}
+	if len(tokens) > 1 {
+		numberOfPackages, err := strconv.Atoi(tokens[1])
+		if err != nil {
+			issues = append(issues, line.issue(1, "numberOfPackages", "parse first input line error: Wrong number of packages input"))
+		}
+		firstLineInput.NumberOfPackages = numberOfPackages
+	}
+	issues = append(issues, line.domainIssues(firstLineIssues(firstLineInput), issues, "baseCost", "numberOfPackages")...)
+	return firstLineInput, sortByColumn(issues)
+}
+
+// Function to place the domain issues of the fields which could be parsed at the token of their field
+// fields has the field of each token of the line
+func (line inputLine) domainIssues(domainIssues []InputIssue, parseIssues []InputIssue, fields ...string) []InputIssue {
+	issues := []InputIssue{}
+	for _, issue := range domainIssues {
+		for token, field := range fields {
+			if field == issue.Field && token < len(line.Tokens) && !hasFieldIssue(parseIssues, field) {
+				issues = append(issues, line.issue(token, field, issue.Message))
+			}
+		}
+	}
+	return issues
+}
+
+// Function to validate a package line and return every issue of it, the package is only usable without issues
+// The line is "packageId(string) weight(decimal) distance(decimal) offerIds(comma seperated string)" and the options
+func validatePackageLine(line inputLine, index int) (PackageDetail, []InputIssue) {
+	issues := []InputIssue{}
+	tokens := line.Tokens
+	if len(tokens) < 4 || len(tokens) > 9 {
+		issues = append(issues, line.issue(-1, "", "parse package inputs error: Wrong number of inputs"))
+	}
+
+	packageDetail := PackageDetail{Index: index, OfferIds: []string{}}
+	if len(tokens) > 0 {
+		packageDetail.Title = tokens[0]
+	}
+	measures := []struct {
+		field string
+		value *float64
+	}{{"weight", &packageDetail.Weight}, {"distance", &packageDetail.Distance}}
+	for i, measure := range measures {
+		if len(tokens) <= i+1 {
+			break
+		}
+		value, err := parseMeasure(tokens[i+1])
+		if err != nil {
+			issues = append(issues, line.issue(i+1, measure.field, fmt.Sprintf("parse package inputs error: Wrong package %s input", measure.field)))
+		}
+		*measure.value = value
+	}
+	issues = append(issues, line.domainIssues(packageIssues(packageDetail), issues, "title", "weight", "distance")...)
+
+	if len(tokens) > 3 && tokens[3] != "" {
+		packageDetail.OfferIds = strings.Split(tokens[3], ",")
+		for i, offerId := range packageDetail.OfferIds {
+			if offerId == "" {
+				issue := line.issue(3, "offers", "parse package inputs error: Empty offer code")
+				if issue.Column > 0 {
+					issue.Column += offerColumn(packageDetail.OfferIds, i)
+				}
+				issues = append(issues, issue)
+			}
+		}
+	}
+	for i := 4; i < len(tokens); i++ {
+		if err := parsePackageOption(&packageDetail, tokens[i]); err != nil {
+			issues = append(issues, line.issue(i, packageOptionField(tokens[i]), err.Error()))
+		}
+	}
+	return packageDetail, sortByColumn(issues)
+}
+
+// Function to sort the issues of a line by their column, the issues of the whole line first
+func sortByColumn(issues []InputIssue) []InputIssue {
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Column < issues[j].Column
+	})
+	return issues
+}
+
+// Function to return the offset of an offer code in the comma separated offers token
+func offerColumn(offerIds []string, index int) int {
+	offset := 0
+	for _, offerId := range offerIds[:index] {
+		offset += len(offerId) + 1
+	}
+	return offset
+}
+
+// Function to return the field of an optional token of a package line, the dimensions don't have a key
+func packageOptionField(token string) string {
+	key, _, isOption := strings.Cut(token, "=")
+	if !isOption {
+		return "dimensions"
+	}
+	return key
+}
+
+// Checks the packages of an input one after the other, for the ids used twice and the offer codes not in the catalog
+type packageChecker struct {
+	offers   *OfferCatalog     // nil doesn't check the offer codes
+	firstAt  map[string]string // Where each package id is used first, like "line 2"
+	Warnings []InputIssue
+}
+
+// Function to make a checker of the packages of an input, nil offers doesn't check the offer codes
+func newPackageChecker(offers *OfferCatalog) *packageChecker {
+	return &packageChecker{offers: offers, firstAt: map[string]string{}}
+}
+
+// Function to check the id and the offer codes of the next valid package of an input, the warnings are kept in the checker
+// at is the position of the package and its title, offersColumn is the column of its offers token or 0
+func (c *packageChecker) check(packageDetail PackageDetail, at InputIssue, offersColumn int) []InputIssue {
+	if at.position() == "" {
+		at.Package = packageDetail.Index + 1
+	}
+	if issue, ok := c.duplicate(packageDetail.Title, at); ok {
+		return []InputIssue{issue}
+	}
+	first := at
+	first.Column = 0
+	c.firstAt[packageDetail.Title] = first.position()
+
+	issues := []InputIssue{}
+	if c.offers != nil {
+		for i, offerId := range packageDetail.OfferIds {
+			if offerId == "" || containsOffer(c.offers.Offers, offerId) {
+				continue
+			}
+			issue := at
+			issue.Field, issue.Column, issue.Warning = "offers", 0, true
+			if offersColumn > 0 {
+				issue.Column = offersColumn + offerColumn(packageDetail.OfferIds, i)
+			}
+			issue.Message = fmt.Sprintf("Unknown offer code %s of package %s, it gives no discount", offerId, packageDetail.Title)
+			issues = append(issues, issue)
+			c.Warnings = append(c.Warnings, issue)
+		}
+	}
+	return issues
+}
+
+// Function to return the issue of a package id which an earlier package already has
+func (c *packageChecker) duplicate(title string, at InputIssue) (InputIssue, bool) {
+	first, ok := c.firstAt[title]
+	if !ok {
+		return InputIssue{}, false
+	}
+	at.Field = "title"
+	at.Message = fmt.Sprintf("Validate package error: Duplicate package id %s, first used on %s", title, first)
+	return at, true
+}
+
+// Function to check a package line with the packages before it, it returns the package and every issue of the line
+// The id of an invalid line is checked but not kept, the line can be given again
+func (c *packageChecker) checkLine(line inputLine, index int) (PackageDetail, []InputIssue) {
+	packageDetail, issues := validatePackageLine(line, index)
+	at := line.issue(0, "", "")
+	if line.Number == 0 {
+		// The console asks the packages one by one, so they are known by their number
+		at.Package = index + 1
+	}
+	if len(issues) > 0 {
+		if issue, ok := c.duplicate(packageDetail.Title, at); ok {
+			issues = append(issues, issue)
+		}
+		return packageDetail, issues
+	}
+	offersColumn := 0
+	if len(line.Columns) > 3 {
+		offersColumn = line.Columns[3]
+	}
+	return packageDetail, c.check(packageDetail, at, offersColumn)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFirstLine(t *testing.T) {
+	t.Run("return every issue of the line", func(t *testing.T) {
+		_, issues := validateFirstLine(newInputLine(1, "-100 x"))
+
+		assert.Equal(t, []InputIssue{
+			{Line: 1, Column: 1, Field: "baseCost", Message: "Validate first line error: The base cost should not be negative, not -100"},
+			{Line: 1, Column: 6, Field: "numberOfPackages", Message: "parse first input line error: Wrong number of packages input"},
+		}, issues)
+	})
+}
+
+func TestValidatePackageLine(t *testing.T) {
+	t.Run("return the weight and distance issues together", func(t *testing.T) {
+		_, issues := validatePackageLine(newInputLine(2, "  PKG1 5s -5 OFR001"), 0)
+
+		assert.Equal(t, []InputIssue{
+			{Line: 2, Column: 8, Field: "weight", Message: "parse package inputs error: Wrong package weight input"},
+			{Line: 2, Column: 11, Field: "distance", Message: "Validate package error: The distance of package PKG1 should be more than 0, not -5"},
+		}, issues)
+	})
+	t.Run("return the column of an empty offer code", func(t *testing.T) {
+		_, issues := validatePackageLine(newInputLine(0, "PKG1 5 5 OFR001,,OFR002 due=x"), 0)
+
+		assert.Equal(t, 2, len(issues))
+		assert.Equal(t, "column 17: parse package inputs error: Empty offer code", issues[0].String())
+		assert.Equal(t, "due", issues[1].Field)
+		assert.Equal(t, 25, issues[1].Column)
+	})
+	t.Run("return the wrong number of inputs with the other issues", func(t *testing.T) {
+		_, issues := validatePackageLine(newInputLine(3, "PKG1 5s"), 0)
+
+		assert.Equal(t, []string{
+			"line 3: parse package inputs error: Wrong number of inputs",
+			"line 3, column 6: parse package inputs error: Wrong package weight input",
+		}, []string{issues[0].String(), issues[1].String()})
+	})
+}
+
+func TestPackageChecker(t *testing.T) {
+	offers := defaultOfferCatalog()
+
+	t.Run("return the package ids used twice", func(t *testing.T) {
+		checker := newPackageChecker(&offers)
+		_, issues := checker.checkLine(newInputLine(2, "PKG1 5 5 OFR001"), 0)
+		assert.Empty(t, issues)
+
+		_, issues = checker.checkLine(newInputLine(3, "PKG1 10 5 OFR001"), 1)
+		assert.Equal(t, []InputIssue{{Line: 3, Column: 1, Field: "title", Message: "Validate package error: Duplicate package id PKG1, first used on line 2"}}, issues)
+	})
+	t.Run("warn about the unknown offer codes", func(t *testing.T) {
+		checker := newPackageChecker(&offers)
+		_, issues := checker.checkLine(newInputLine(0, "PKG1 5 5 OFR001,OFR008"), 0)
+
+		assert.Equal(t, "package 1, column 17: warning: Unknown offer code OFR008 of package PKG1, it gives no discount", issues[0].String())
+		assert.Equal(t, issues, checker.Warnings)
+		assert.NoError(t, validationError(issues))
+	})
+	t.Run("don't check the offer codes without offers", func(t *testing.T) {
+		checker := newPackageChecker(nil)
+		_, issues := checker.checkLine(newInputLine(1, "PKG1 5 5 OFR008"), 0)
+
+		assert.Empty(t, issues)
+	})
+}
+
+func TestValidationError(t *testing.T) {
+	t.Run("write one issue per line", func(t *testing.T) {
+		err := validationError([]InputIssue{
+			{Line: 2, Column: 6, Message: "parse package inputs error: Wrong package weight input"},
+			{Package: 1, Message: "Unknown offer code", Warning: true},
+			{Message: "parse first input line error: Wrong number of inputs"},
+		})
+
+		assert.EqualError(t, err, "line 2, column 6: parse package inputs error: Wrong package weight input\nparse first input line error: Wrong number of inputs")
+	})
+}
@@ -2,36 +2,89 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
-type ProblemSolver func(FirstLineInput, []PackageDetail, [][]string) ([]string, error)
-type Problem struct {
-	Key        string
-	Title      string
-	ExtraLines int
-	Solver     ProblemSolver
+type ProblemSolver func(FirstLineInput, []PackageDetail, [][]string, SolverOptions) (Result, error)
+
+// The typed outputs of a solver, only the list of the solved problem is set
+type Result struct {
+	Currency   string              `json:"currency,omitempty"` // The currency code of the amounts, if the pricing model has one
+	TimeUnit   TimeUnit            `json:"timeUnit,omitempty"` // The unit of the times of the shipments and dispatches
+	Costs      []CalculationOutput `json:"costs,omitempty"`
+	Shipments  []ShipmentDetail    `json:"shipments,omitempty"`
+	Dispatches []Dispatch          `json:"dispatches,omitempty"`
+	Sla        *SlaReport          `json:"sla,omitempty"` // The check of the delivery windows, if any package has one
+	// The improvement of the optimizer over the greedy plan, if the optimizer is enabled
+	Optimization *OptimizationReport `json:"optimization,omitempty"`
+}
+
+// Settings which are loaded once at startup and shared by all solvers
+type SolverOptions struct {
+	Offers OfferCatalog
+	// The pricing models and the one which is used, a zero PricingModel is the pricing of the problem statement
+	Pricing      PricingCatalog
+	PricingModel PricingModel
+	// Time limit of the exact shipment packing, after it the shipments are packed greedily (0 means no limit)
+	PackingTimeBudget time.Duration
+	// Leave out the packages no vehicle can carry and mark them undeliverable, instead of failing
+	SkipUnshippable bool
+	// The day of the order for the validity dates of the offers, set once before solving so every package has the same day
+	OrderDate time.Time
+	// The segment tags of the customer, e.g. "business" or "vip", for the offers limited to some segments
+	CustomerSegments []string
+	// How the delivery times are rounded and shown, a zero TimeModel is the one of the problem statement
+	TimeModel TimeModel
+	// The daily shifts of the vehicles without own shifts ("HH:MM-HH:MM") and the days with shifts, empty means every day
+	Shifts      []string
+	WorkingDays []time.Weekday
+	// Take the packages which would miss their due time on a later trip first, instead of the fullest shipment
+	PrioritizeDeadlines bool
+	// Time the trips along a route through the package locations instead of a direct leg to each package
+	Routing bool
+	// How the packages of each shipment are picked, nil is the packing of the problem statement
+	Objective PackingObjective
+	// Improve the greedy plan with simulated annealing
+	Optimizer OptimizerOptions
 }
 
 func main() {
-	// List of problems
-	problems := []Problem{
-		{
-			Key:        "1",
-			Title:      "Delivery Cost Estimation with Offers",
-			ExtraLines: 0,
-			Solver:     CalculateDeliveryCost,
-		},
-		{
-			Key:        "2",
-			Title:      "Delivery Time Estimation",
-			ExtraLines: 1,
-			Solver:     CalculateDeliveryTime,
-		},
+	commands := listCommands()
+	if len(os.Args) > 1 {
+		if command, ok := findCommand(commands, os.Args[1]); ok {
+			os.Exit(command.Run(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+		}
+	}
+
+	solver := addSolverFlags(flag.CommandLine)
+	problemKey := flag.String("problem", "", "problem number to solve without prompts (batch mode)")
+	inputPath := flag.String("input", "-", "input file of the batch mode, use - for stdin")
+	manifestPath := flag.String("manifest", "", "CSV (.csv) or JSON Lines (.jsonl) file with the packages of the batch mode, the input then has no package lines")
+	problems := listProblems()
+	flag.Usage = func() {
+		writeUsage(flag.CommandLine.Output(), commands, problems)
+		fmt.Fprintln(flag.CommandLine.Output(), "\nFlags without a command:")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	options, renderer, status := solver.build(os.Stderr)
+	if status != 0 {
+		os.Exit(status)
+	}
+
+	if *manifestPath != "" && *problemKey == "" {
+		fmt.Fprintln(os.Stderr, "manifest error: --manifest needs the batch mode, set --problem")
+		os.Exit(2)
+	}
+	if *problemKey != "" {
+		os.Exit(runBatch(problems, *problemKey, *inputPath, *manifestPath, options, renderer, os.Stdin, os.Stdout, os.Stderr))
 	}
 
 	reader := bufio.NewReader(os.Stdin)
@@ -39,18 +92,64 @@ func main() {
 	// Get problem
 	problem := pickProblem(reader, problems)
 	// Get problems info
-	firstLineInput, packageDetails, extraDetails := readProblemInputs(reader, problem)
+	firstLineInput, packageDetails, extraDetails := readProblemInputs(reader, problem, options.Offers)
 	// Solve the problem
-	outputs, err := problem.Solver(firstLineInput, packageDetails, extraDetails)
+	result, err := problem.Solver(firstLineInput, packageDetails, extraDetails, options)
 	if err != nil {
 		fmt.Println(err)
 	} else {
 		// Write the outputs in console
 		fmt.Println("<----------- Output ----------->")
-		for _, output := range outputs {
-			fmt.Println(output)
+		if err := renderer(os.Stdout, result); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+}
+
+// Function to build the solver options, the default offers and pricing are used if no file path is given
+func loadSolverOptions(offersPath string, pricingPath string) (SolverOptions, error) {
+	options := SolverOptions{Offers: defaultOfferCatalog(), Pricing: defaultPricingCatalog()}
+	if offersPath != "" {
+		offers, err := LoadOfferCatalog(offersPath)
+		if err != nil {
+			return options, err
 		}
+		options.Offers = offers
 	}
+	if pricingPath != "" {
+		pricing, err := LoadPricingCatalog(pricingPath)
+		if err != nil {
+			return options, err
+		}
+		options.Pricing = pricing
+	}
+	return options, setPricingModel(&options, "")
+}
+
+// Function to set the order date and customer segments which the offer rules are checked against
+// Without an order date it's today, the date of the run or of the API request
+func setOrderOptions(options *SolverOptions, orderDate string, customerSegments []string) error {
+	options.OrderDate = time.Now()
+	if orderDate != "" {
+		date, err := time.Parse(ruleDateLayout, orderDate)
+		if err != nil {
+			return fmt.Errorf("parse order date error: '%s' should be a date like 2006-01-02", orderDate)
+		}
+		options.OrderDate = date
+	}
+	options.CustomerSegments = customerSegments
+	return nil
+}
+
+// Function to split a comma separated list like the segment tags or the shifts, empty items are dropped
+func splitCommaList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 // Function to show options to the user to select one of the problems
@@ -81,47 +180,46 @@ func displayProblems(problems []Problem) {
 // Function to read the problem number from stdin and return the selected problem
 func getSelectedProblem(reader *bufio.Reader, problems []Problem) (Problem, error) {
 	problemNumber := strings.TrimSpace(readLine(reader))
-
-	for _, problem := range problems {
-		if problemNumber == problem.Key {
-			return problem, nil
-		}
-	}
-
-	return Problem{}, fmt.Errorf("read problem error: '%s' is not a known problem number", problemNumber)
+	return findProblem(problems, problemNumber)
 }
 
 // Function to read the problem inputs from stdin, validate and parse them
 // extra detail line is just read in this function and validatation is handled in the solver function
-func readProblemInputs(reader *bufio.Reader, problem Problem) (FirstLineInput, []PackageDetail, [][]string) {
-	fmt.Println("<----------- Please enter base cost and number of packages ----------->")
+// The offer codes which are not in the offers are shown as warnings
+// The prompts and formats come from the input schema of the problem
+func readProblemInputs(reader *bufio.Reader, problem Problem, offers OfferCatalog) (FirstLineInput, []PackageDetail, [][]string) {
+	schema := problem.Schema
+	fmt.Printf("<----------- Please enter %s ----------->\n", schema.FirstLine.Name)
+	fmt.Println(schema.FirstLine.hint())
 	firstLineInput := getFirstLineInput(reader)
 
-	printData := fmt.Sprintf("<----------- Please enter %d package details ----------->", firstLineInput.NumberOfPackages)
+	printData := fmt.Sprintf("<----------- Please enter %d %s details ----------->", firstLineInput.NumberOfPackages, schema.Package.Name)
 	fmt.Println(printData)
+	if firstLineInput.NumberOfPackages > 0 {
+		fmt.Println(schema.Package.hint())
+	}
+	checker := newPackageChecker(&offers)
 	packageDetails := []PackageDetail{}
 	for len(packageDetails) < firstLineInput.NumberOfPackages {
-		packageDetail := getPackageDetail(reader, len(packageDetails))
+		packageDetail := getPackageDetail(reader, len(packageDetails), checker)
 		packageDetails = append(packageDetails, packageDetail)
 	}
 
 	extraDetails := [][]string{}
-	if problem.ExtraLines > 0 {
-		fmt.Println("<----------- Please enter shipment detail ----------->")
-		for len(extraDetails) < problem.ExtraLines {
-			inputTokens := strings.Split(strings.TrimSpace(readLine(reader)), " ")
-			extraDetails = append(extraDetails, inputTokens)
-		}
+	for _, extraLine := range schema.ExtraLines {
+		fmt.Printf("<----------- Please enter %s ----------->\n", extraLine.Name)
+		fmt.Println(extraLine.hint())
+		inputTokens := splitInputLine(readLine(reader))
+		extraDetails = append(extraDetails, inputTokens)
 	}
 
 	return firstLineInput, packageDetails, extraDetails
 }
 
-// Function to read first line of input from stdin
+// Function to read first line of input from stdin, every issue of the line is shown before asking again
 func getFirstLineInput(reader *bufio.Reader) FirstLineInput {
-	inputTokens := strings.Split(strings.TrimSpace(readLine(reader)), " ")
-	firstLineInput, err := parseFirstLineInput(inputTokens)
-	if err != nil {
+	firstLineInput, issues := validateFirstLine(newInputLine(0, readLine(reader)))
+	if err := validationError(issues); err != nil {
 		fmt.Println(err)
 		return getFirstLineInput(reader)
 	}
@@ -129,71 +227,97 @@ func getFirstLineInput(reader *bufio.Reader) FirstLineInput {
 }
 
 // Function to validate and parse the first line of inputs
-// first line of input should have two integer "baseCost(int) numberOfPackages(int)"
+// first line of input should have "baseCost(decimal) numberOfPackages(int)"
 func parseFirstLineInput(inputTokens []string) (FirstLineInput, error) {
-	var firstLineInput FirstLineInput
-	if len(inputTokens) != 2 {
-		return firstLineInput, fmt.Errorf("parse first input line error: Wrong number of inputs")
-	}
-
-	baseCost, err := strconv.Atoi(inputTokens[0])
-	if err != nil {
-		return firstLineInput, fmt.Errorf("parse first input line error: Wrong base cost input")
-	}
-
-	numberOfPackages, err := strconv.Atoi(inputTokens[1])
-	if err != nil {
-		return firstLineInput, fmt.Errorf("parse first input line error: Wrong number of packages input")
-	}
-
-	firstLineInput = FirstLineInput{
-		BaseCost:         baseCost,
-		NumberOfPackages: numberOfPackages,
+	firstLineInput, issues := validateFirstLine(inputLine{Tokens: inputTokens})
+	if err := validationError(issues); err != nil {
+		return FirstLineInput{}, err
 	}
 	return firstLineInput, nil
 }
 
-// Function to read package details from stdin
-func getPackageDetail(reader *bufio.Reader, index int) PackageDetail {
+// Function to read package details from stdin, every issue of the line is shown before asking again
+// The checker finds the ids of the packages before and warns about the unknown offer codes
+func getPackageDetail(reader *bufio.Reader, index int, checker *packageChecker) PackageDetail {
 	printData := fmt.Sprintf("Package %d:", index+1)
 	fmt.Println(printData)
 
-	inputTokens := strings.Split(strings.TrimSpace(readLine(reader)), " ")
-	packageDetail, err := parsePackageDetail(inputTokens, index)
-	if err != nil {
+	packageDetail, issues := checker.checkLine(newInputLine(0, readLine(reader)), index)
+	if err := validationError(issues); err != nil {
 		fmt.Println(err)
-		return getPackageDetail(reader, index)
+		return getPackageDetail(reader, index, checker)
+	}
+	for _, issue := range issues {
+		fmt.Println(issue)
 	}
 	return packageDetail
 }
 
-// Function to parse package detail input "packageId(string) weight(int) distance(int) offerIds(comma seperated string)"
+// Function to parse package detail input "packageId(string) weight(decimal) distance(decimal) offerIds(comma seperated string)"
+// The optional tokens after them are the dimensions "LxWxH", the delivery window "after=TIME" and "due=TIME"
+// the priority "priority=express" and the location "at=X,Y"
+// The error is a ValidationError with every issue of the tokens
 func parsePackageDetail(inputTokens []string, index int) (PackageDetail, error) {
-	var packageDetail PackageDetail
-	if len(inputTokens) != 4 {
-		return packageDetail, fmt.Errorf("parse package inputs error: Wrong number of inputs")
+	packageDetail, issues := validatePackageLine(inputLine{Tokens: inputTokens}, index)
+	if err := validationError(issues); err != nil {
+		return PackageDetail{}, err
 	}
+	return packageDetail, nil
+}
 
-	weight, err := strconv.Atoi(inputTokens[1])
-	if err != nil {
-		return packageDetail, fmt.Errorf("parse package inputs error: Wrong package weight input")
+// Function to parse an optional token of a package line, "key=value" or the dimensions
+func parsePackageOption(packageDetail *PackageDetail, token string) error {
+	key, value, isOption := strings.Cut(token, "=")
+	switch {
+	case !isOption && packageDetail.Dimensions == nil:
+		dimensions, err := parseDimensions(token)
+		if err != nil {
+			return err
+		}
+		packageDetail.Dimensions = &dimensions
+	case key == "after" && packageDetail.NotBefore == "":
+		if err := checkPackageTime(value); err != nil {
+			return fmt.Errorf("parse package inputs error: Wrong package after input, %w", err)
+		}
+		packageDetail.NotBefore = value
+	case key == "due" && packageDetail.DueBy == "":
+		if err := checkPackageTime(value); err != nil {
+			return fmt.Errorf("parse package inputs error: Wrong package due input, %w", err)
+		}
+		packageDetail.DueBy = value
+	case key == "at" && packageDetail.Location == nil:
+		location, err := parsePoint(value)
+		if err != nil {
+			return fmt.Errorf("parse package inputs error: Wrong package location input, %w", err)
+		}
+		packageDetail.Location = &location
+	case key == "priority" && packageDetail.Priority == "":
+		priority, err := parsePriority(value)
+		if err != nil {
+			return fmt.Errorf("parse package inputs error: Wrong package priority input, %w", err)
+		}
+		packageDetail.Priority = priority
+	default:
+		return fmt.Errorf("parse package inputs error: Wrong package option '%s', use LxWxH, after=TIME, due=TIME, priority=LEVEL or at=X,Y once", token)
 	}
+	return nil
+}
 
-	distance, err := strconv.Atoi(inputTokens[2])
-	if err != nil {
-		return packageDetail, fmt.Errorf("parse package inputs error: Wrong package distance input")
+// Function to parse the dimensions of a package "length x width x height", e.g. "40x30x20"
+func parseDimensions(input string) (Dimensions, error) {
+	parts := strings.Split(input, "x")
+	if len(parts) != 3 {
+		return Dimensions{}, fmt.Errorf("parse package inputs error: Wrong package dimensions input, use LxWxH")
 	}
-
-	offerIds := strings.Split(inputTokens[3], ",")
-
-	packageDetail = PackageDetail{
-		Index:    index,
-		Title:    inputTokens[0],
-		Weight:   weight,
-		Distance: distance,
-		OfferIds: offerIds,
+	sizes := [3]int{}
+	for i, part := range parts {
+		size, err := strconv.Atoi(part)
+		if err != nil || size <= 0 {
+			return Dimensions{}, fmt.Errorf("parse package inputs error: Wrong package dimensions input, use LxWxH")
+		}
+		sizes[i] = size
 	}
-	return packageDetail, nil
+	return Dimensions{Length: sizes[0], Width: sizes[1], Height: sizes[2]}, nil
 }
 
 // Function to read a line from stdin
@@ -205,3 +329,8 @@ func readLine(reader *bufio.Reader) string {
 
 	return strings.TrimRight(string(str), "\r\n")
 }
+
+// Function to split an input line into space separated tokens
+func splitInputLine(line string) []string {
+	return strings.Split(strings.TrimSpace(line), " ")
+}
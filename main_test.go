@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -11,16 +12,15 @@ import (
 func TestPickProblem(t *testing.T) {
 	problems := []Problem{
 		{
-			Key:        "1",
-			Title:      "Delivery Cost Estimation with Offers",
-			ExtraLines: 0,
-			Solver:     CalculateDeliveryCost,
+			Key:    "1",
+			Title:  "Delivery Cost Estimation with Offers",
+			Solver: CalculateDeliveryCost,
 		},
 		{
-			Key:        "2",
-			Title:      "Delivery Time Estimation",
-			ExtraLines: 1,
-			Solver:     CalculateDeliveryTime,
+			Key:    "2",
+			Title:  "Delivery Time Estimation",
+			Schema: InputSchema{ExtraLines: []LineSchema{shipmentLineSchema}},
+			Solver: CalculateDeliveryTime,
 		},
 	}
 	t.Run("return problem for the valid problem number", func(t *testing.T) {
@@ -35,16 +35,15 @@ func TestPickProblem(t *testing.T) {
 func TestGetSelectedProblem(t *testing.T) {
 	problems := []Problem{
 		{
-			Key:        "1",
-			Title:      "Delivery Cost Estimation with Offers",
-			ExtraLines: 0,
-			Solver:     CalculateDeliveryCost,
+			Key:    "1",
+			Title:  "Delivery Cost Estimation with Offers",
+			Solver: CalculateDeliveryCost,
 		},
 		{
-			Key:        "2",
-			Title:      "Delivery Time Estimation",
-			ExtraLines: 1,
-			Solver:     CalculateDeliveryTime,
+			Key:    "2",
+			Title:  "Delivery Time Estimation",
+			Schema: InputSchema{ExtraLines: []LineSchema{shipmentLineSchema}},
+			Solver: CalculateDeliveryTime,
 		},
 	}
 	t.Run("return error for empty problem number", func(t *testing.T) {
@@ -80,7 +79,7 @@ func TestGetFirstLineInput(t *testing.T) {
 		inputTokens := getFirstLineInput(reader)
 
 		assert.Equal(t, FirstLineInput{
-			BaseCost:         100,
+			BaseCost:         moneyOf(100),
 			NumberOfPackages: 5,
 		}, inputTokens)
 	})
@@ -113,7 +112,13 @@ func TestParseFirstInputLine(t *testing.T) {
 		firstLineInput, err := parseFirstLineInput(inputTokens)
 
 		assert.NoError(t, err)
-		assert.Equal(t, FirstLineInput{BaseCost: 100, NumberOfPackages: 3}, firstLineInput)
+		assert.Equal(t, FirstLineInput{BaseCost: moneyOf(100), NumberOfPackages: 3}, firstLineInput)
+	})
+	t.Run("return a decimal base cost", func(t *testing.T) {
+		firstLineInput, err := parseFirstLineInput([]string{"99.95", "3"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, Money(9995), firstLineInput.BaseCost)
 	})
 }
 
@@ -121,7 +126,7 @@ func TestGetPackageDetails(t *testing.T) {
 	t.Run("return packageDetail for the valid input", func(t *testing.T) {
 		packageDetailsInput := "PKG1 50 30 OFR001"
 		reader := bufio.NewReader(strings.NewReader(packageDetailsInput))
-		inputTokens := getPackageDetail(reader, 0)
+		inputTokens := getPackageDetail(reader, 0, newPackageChecker(nil))
 
 		assert.Equal(t, PackageDetail{
 			Index:    0,
@@ -133,6 +138,19 @@ func TestGetPackageDetails(t *testing.T) {
 	})
 }
 
+func TestGetPackageDetailsAgain(t *testing.T) {
+	t.Run("ask again after an invalid line and a package id used twice", func(t *testing.T) {
+		reader := bufio.NewReader(strings.NewReader("PKG1 5s 30 OFR001\nPKG1 50 30 OFR001\nPKG2 50 30 OFR001\n"))
+		checker := newPackageChecker(nil)
+		checker.check(PackageDetail{Title: "PKG1"}, InputIssue{Package: 1}, 0)
+
+		packageDetail := getPackageDetail(reader, 1, checker)
+
+		assert.Equal(t, "PKG2", packageDetail.Title)
+		assert.Equal(t, 1, packageDetail.Index)
+	})
+}
+
 func TestParsePackageDetail(t *testing.T) {
 	t.Run("doesn't check wrong number of inputs in the package detail input", func(t *testing.T) {
 		inputTokens := []string{"PKG1", "50", "30"}
@@ -168,4 +186,83 @@ func TestParsePackageDetail(t *testing.T) {
 			OfferIds: []string{"OFR001"},
 		}, packageDetail)
 	})
+	t.Run("return decimal weight and distance", func(t *testing.T) {
+		packageDetail, err := parsePackageDetail([]string{"PKG1", "50.5", "30.25", "OFR001"}, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 50.5, packageDetail.Weight)
+		assert.Equal(t, 30.25, packageDetail.Distance)
+	})
+	t.Run("doesn't check weight with more than 3 decimals", func(t *testing.T) {
+		_, err := parsePackageDetail([]string{"PKG1", "50.1234", "30", "OFR001"}, 0)
+
+		assert.EqualError(t, err, "parse package inputs error: Wrong package weight input")
+	})
+	t.Run("return the dimensions of the package", func(t *testing.T) {
+		packageDetail, err := parsePackageDetail([]string{"PKG1", "50", "30", "OFR001", "40x30x20"}, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &Dimensions{Length: 40, Width: 30, Height: 20}, packageDetail.Dimensions)
+	})
+	t.Run("doesn't check wrong dimensions in the package detail input", func(t *testing.T) {
+		packageDetail, err := parsePackageDetail([]string{"PKG1", "50", "30", "OFR001", "40x30"}, 0)
+
+		assert.EqualError(t, err, "parse package inputs error: Wrong package dimensions input, use LxWxH")
+		assert.Equal(t, PackageDetail{}, packageDetail)
+	})
+	t.Run("return the delivery window of the package", func(t *testing.T) {
+		packageDetail, err := parsePackageDetail([]string{"PKG1", "50", "30", "OFR001", "due=2024-03-15T12:00:00Z", "40x30x20", "after=1.5"}, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "1.5", packageDetail.NotBefore)
+		assert.Equal(t, "2024-03-15T12:00:00Z", packageDetail.DueBy)
+		assert.NotNil(t, packageDetail.Dimensions)
+	})
+	t.Run("return the priority of the package", func(t *testing.T) {
+		packageDetail, err := parsePackageDetail([]string{"PKG1", "50", "30", "OFR001", "priority=same-day"}, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, PrioritySameDay, packageDetail.Priority)
+
+		_, err = parsePackageDetail([]string{"PKG1", "50", "30", "OFR001", "priority=urgent"}, 0)
+		assert.EqualError(t, err, "parse package inputs error: Wrong package priority input, Unknown priority 'urgent', use standard, express or same-day")
+	})
+	t.Run("return the location of the package", func(t *testing.T) {
+		packageDetail, err := parsePackageDetail([]string{"PKG1", "50", "30", "OFR001", "at=12.5,-3"}, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &Point{X: 12.5, Y: -3}, packageDetail.Location)
+	})
+	t.Run("doesn't check wrong package options", func(t *testing.T) {
+		_, err := parsePackageDetail([]string{"PKG1", "50", "30", "OFR001", "due=noon"}, 0)
+		assert.EqualError(t, err, "parse package inputs error: Wrong package due input, 'noon' should be hours like 4.5 or a time like 2006-01-02T15:04:05Z")
+
+		_, err = parsePackageDetail([]string{"PKG1", "50", "30", "OFR001", "due=1", "due=2"}, 0)
+		assert.EqualError(t, err, "parse package inputs error: Wrong package option 'due=2', use LxWxH, after=TIME, due=TIME, priority=LEVEL or at=X,Y once")
+	})
+}
+
+func TestSetOrderOptions(t *testing.T) {
+	t.Run("set the order date and segments", func(t *testing.T) {
+		options := SolverOptions{}
+		err := setOrderOptions(&options, "2024-04-01", []string{"vip"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), options.OrderDate)
+		assert.Equal(t, []string{"vip"}, options.CustomerSegments)
+	})
+	t.Run("default the order date to today once for all packages", func(t *testing.T) {
+		options := SolverOptions{}
+		before := time.Now().Format(ruleDateLayout)
+		err := setOrderOptions(&options, "", nil)
+		after := time.Now().Format(ruleDateLayout)
+
+		assert.NoError(t, err)
+		assert.Contains(t, []string{before, after}, options.OrderDate.Format(ruleDateLayout))
+	})
+	t.Run("return error for a wrong order date", func(t *testing.T) {
+		err := setOrderOptions(&SolverOptions{}, "01/04/2024", nil)
+
+		assert.EqualError(t, err, "parse order date error: '01/04/2024' should be a date like 2006-01-02")
+	})
 }
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The issues of the invalid rows of a package manifest, every row is checked before the manifest is rejected
+type ManifestError struct {
+	Path   string
+	Issues []InputIssue
+}
+
+func (e *ManifestError) Error() string {
+	rows := map[int]bool{}
+	for _, issue := range e.Issues {
+		rows[issue.Line] = true
+	}
+	return fmt.Sprintf("manifest error: %s has %d invalid row(s):\n%s", e.Path, len(rows), &ValidationError{Issues: e.Issues})
+}
+
+// The CSV columns of the package fields by their header names, the headers are matched without case, spaces, "-" and "_"
+var manifestColumns = map[string]string{
+	"title": "title", "id": "title", "package": "title", "packageid": "title",
+	"weight":   "weight",
+	"distance": "distance",
+	"offers":   "offers", "offerids": "offers", "offercodes": "offers",
+	"dimensions": "dimensions",
+	"after":      "after", "notbefore": "after",
+	"due": "due", "dueby": "due",
+	"priority": "priority",
+	"location": "at", "at": "at",
+}
+
+// Function to read the packages of a manifest file, CSV (.csv) or JSON Lines (.jsonl or .ndjson)
+func LoadManifest(path string) ([]PackageDetail, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("manifest error: %w", err)
+	}
+	defer file.Close()
+
+	var packageDetails []PackageDetail
+	var issues []InputIssue
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		packageDetails, issues, err = readCSVManifest(file)
+	case ".jsonl", ".ndjson":
+		packageDetails, issues, err = readJSONLManifest(file)
+	default:
+		return nil, fmt.Errorf("manifest error: Unknown manifest format '%s', use .csv, .jsonl or .ndjson", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("manifest error: %s: %w", path, err)
+	}
+	if len(issues) > 0 {
+		return nil, &ManifestError{Path: path, Issues: issues}
+	}
+	return packageDetails, nil
+}
+
+// Function to read a CSV manifest with a header line, the title, weight and distance columns are required
+// The offers are separated by commas, semicolons or spaces, the other columns take the values of the package line options
+// It returns the packages and every issue of the invalid rows at their column, or an error if the file can't be read at all
+func readCSVManifest(input io.Reader) ([]PackageDetail, []InputIssue, error) {
+	reader := csv.NewReader(input)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("Missing header line")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	columns := make([]string, len(header))
+	for i, name := range header {
+		key := strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+		if columns[i] = manifestColumns[key]; columns[i] == "" {
+			return nil, nil, fmt.Errorf("Unknown column '%s'", name)
+		}
+		if containsString(columns[:i], columns[i]) {
+			return nil, nil, fmt.Errorf("Duplicate %s column '%s'", columns[i], name)
+		}
+	}
+	for _, required := range []string{"title", "weight", "distance"} {
+		if !containsString(columns, required) {
+			return nil, nil, fmt.Errorf("Missing %s column", required)
+		}
+	}
+
+	checker := newPackageChecker(nil)
+	packageDetails := []PackageDetail{}
+	issues := []InputIssue{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			issues = append(issues, InputIssue{Line: parseErr.Line, Column: parseErr.Column, Message: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if len(record) != len(columns) {
+			issues = append(issues, InputIssue{Line: line, Message: fmt.Sprintf("parse package inputs error: Wrong number of columns, expected %d", len(columns))})
+			continue
+		}
+		// The issues of a field are shown at its column
+		locate := func(rowIssues []InputIssue) []InputIssue {
+			for i, issue := range rowIssues {
+				for c, column := range columns {
+					if column == issue.Field {
+						_, rowIssues[i].Column = reader.FieldPos(c)
+					}
+				}
+			}
+			return sortByColumn(rowIssues)
+		}
+
+		fields := map[string]string{}
+		for i, value := range record {
+			fields[columns[i]] = strings.TrimSpace(value)
+		}
+		offerIds := strings.FieldsFunc(fields["offers"], func(r rune) bool { return r == ',' || r == ';' || r == ' ' })
+		inputTokens := []string{fields["title"], fields["weight"], fields["distance"], strings.Join(offerIds, ",")}
+		for _, option := range []string{"after", "due", "priority", "at"} {
+			if fields[option] != "" {
+				inputTokens = append(inputTokens, option+"="+fields[option])
+			}
+		}
+		if fields["dimensions"] != "" {
+			inputTokens = append(inputTokens, fields["dimensions"])
+		}
+
+		packageDetail, rowIssues := validatePackageLine(inputLine{Number: line, Tokens: inputTokens}, len(packageDetails))
+		if len(rowIssues) == 0 {
+			rowIssues = checker.check(packageDetail, InputIssue{Line: line, Field: "title"}, 0)
+		}
+		if len(rowIssues) > 0 {
+			issues = append(issues, locate(rowIssues)...)
+			continue
+		}
+		packageDetails = append(packageDetails, packageDetail)
+	}
+	return packageDetails, issues, nil
+}
+
+// Function to read a JSON Lines manifest, one package object per line with the fields of the API
+// Blank lines are ignored, it returns the packages and every issue of the invalid lines
+func readJSONLManifest(input io.Reader) ([]PackageDetail, []InputIssue, error) {
+	scanner := bufio.NewScanner(input)
+	checker := newPackageChecker(nil)
+	packageDetails := []PackageDetail{}
+	issues := []InputIssue{}
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var packageDetail PackageDetail
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&packageDetail); err != nil {
+			issues = append(issues, InputIssue{Line: line, Message: fmt.Sprintf("parse package inputs error: %s", err)})
+			continue
+		}
+		packageDetail.Index = len(packageDetails)
+		rowIssues := validatePackageFields(packageDetail)
+		if len(rowIssues) == 0 {
+			rowIssues = checker.check(packageDetail, InputIssue{Line: line}, 0)
+		}
+		if len(rowIssues) > 0 {
+			for _, issue := range rowIssues {
+				issue.Line = line
+				issues = append(issues, issue)
+			}
+			continue
+		}
+		if packageDetail.OfferIds == nil {
+			packageDetail.OfferIds = []string{}
+		}
+		packageDetails = append(packageDetails, packageDetail)
+	}
+	return packageDetails, issues, scanner.Err()
+}
+
+// Function to check a list has the given value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
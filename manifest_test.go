@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCSVManifest(t *testing.T) {
+	t.Run("map the columns by their headers", func(t *testing.T) {
+		input := "Package ID,weight,Distance,offer_codes,due by,priority\n" +
+			"\"Big red box\",50,30,\"OFR001;OFR003\",4.5,express\n" +
+			"PKG2, 75.5, 125,,,\n"
+		packageDetails, rowErrors, err := readCSVManifest(strings.NewReader(input))
+
+		assert.NoError(t, err)
+		assert.Empty(t, rowErrors)
+		assert.Equal(t, 2, len(packageDetails))
+		assert.Equal(t, "Big red box", packageDetails[0].Title)
+		assert.Equal(t, []string{"OFR001", "OFR003"}, packageDetails[0].OfferIds)
+		assert.Equal(t, "4.5", packageDetails[0].DueBy)
+		assert.Equal(t, Priority("express"), packageDetails[0].Priority)
+		assert.Equal(t, 1, packageDetails[1].Index)
+		assert.Equal(t, 75.5, packageDetails[1].Weight)
+		assert.Equal(t, []string{}, packageDetails[1].OfferIds)
+	})
+	t.Run("report every invalid row with its line", func(t *testing.T) {
+		input := "title,weight,distance,location\n" +
+			"PKG1,5s,5,\n" +
+			"PKG2,10,5,1,2\n" +
+			"PKG3,10,5,\"3,4\"\n" +
+			",10,5,\n" +
+			"PKG5,10,5s,\n"
+		packageDetails, rowErrors, err := readCSVManifest(strings.NewReader(input))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(packageDetails))
+		assert.Equal(t, &Point{X: 3, Y: 4}, packageDetails[0].Location)
+		lines := []int{}
+		for _, rowError := range rowErrors {
+			lines = append(lines, rowError.Line)
+		}
+		assert.Equal(t, []int{2, 3, 5, 6}, lines)
+		assert.Equal(t, "line 2, column 6: parse package inputs error: Wrong package weight input", rowErrors[0].String())
+		assert.Equal(t, "line 3: parse package inputs error: Wrong number of columns, expected 4", rowErrors[1].String())
+		assert.Equal(t, "line 5, column 1: Validate package error: Missing package id", rowErrors[2].String())
+	})
+	t.Run("report a package id used twice", func(t *testing.T) {
+		_, rowErrors, err := readCSVManifest(strings.NewReader("title,weight,distance\nPKG1,5,5\nPKG1,10,5\n"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "line 3, column 1: Validate package error: Duplicate package id PKG1, first used on line 2", rowErrors[0].String())
+	})
+	t.Run("return error for a missing required column", func(t *testing.T) {
+		_, _, err := readCSVManifest(strings.NewReader("title,weight\nPKG1,5\n"))
+
+		assert.EqualError(t, err, "Missing distance column")
+	})
+	t.Run("return error for an unknown or duplicate column", func(t *testing.T) {
+		_, _, err := readCSVManifest(strings.NewReader("title,weight,distance,color\n"))
+		assert.EqualError(t, err, "Unknown column 'color'")
+
+		_, _, err = readCSVManifest(strings.NewReader("title,id,weight,distance\n"))
+		assert.EqualError(t, err, "Duplicate title column 'id'")
+	})
+}
+
+func TestReadJSONLManifest(t *testing.T) {
+	t.Run("read one package per line", func(t *testing.T) {
+		input := "{\"title\":\"PKG 1\",\"weight\":5,\"distance\":5,\"offerIds\":[\"OFR001\"]}\n\n{\"title\":\"PKG2\",\"weight\":15,\"distance\":5}\n"
+		packageDetails, rowErrors, err := readJSONLManifest(strings.NewReader(input))
+
+		assert.NoError(t, err)
+		assert.Empty(t, rowErrors)
+		assert.Equal(t, 2, len(packageDetails))
+		assert.Equal(t, "PKG 1", packageDetails[0].Title)
+		assert.Equal(t, 1, packageDetails[1].Index)
+		assert.Equal(t, []string{}, packageDetails[1].OfferIds)
+	})
+	t.Run("report every invalid line", func(t *testing.T) {
+		input := "{\"title\":\"PKG1\",\"weight\":5,\"distance\":5,\"color\":\"red\"}\n{\"title\":\"PKG2\",\"weight\":15,\"distance\":5}\nnot json\n"
+		packageDetails, rowErrors, err := readJSONLManifest(strings.NewReader(input))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(packageDetails))
+		assert.Equal(t, 2, len(rowErrors))
+		assert.Equal(t, 1, rowErrors[0].Line)
+		assert.Equal(t, 3, rowErrors[1].Line)
+	})
+}
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+
+	t.Run("read a manifest by its extension", func(t *testing.T) {
+		packageDetails, err := LoadManifest(write("packages.ndjson", "{\"title\":\"PKG1\",\"weight\":5,\"distance\":5}\n"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(packageDetails))
+	})
+	t.Run("return every invalid row", func(t *testing.T) {
+		path := write("packages.csv", "title,weight,distance\nPKG1,5s,5\nPKG2,-5,5s\n")
+		_, err := LoadManifest(path)
+
+		var manifestError *ManifestError
+		assert.True(t, errors.As(err, &manifestError))
+		assert.Equal(t, 3, len(manifestError.Issues))
+		assert.EqualError(t, err, "manifest error: "+path+" has 2 invalid row(s):\n"+
+			"line 2, column 6: parse package inputs error: Wrong package weight input\n"+
+			"line 3, column 6: Validate package error: The weight of package PKG2 should be more than 0, not -5\n"+
+			"line 3, column 9: parse package inputs error: Wrong package distance input")
+	})
+	t.Run("return error for an unknown format", func(t *testing.T) {
+		_, err := LoadManifest(write("packages.xml", ""))
+
+		assert.EqualError(t, err, "manifest error: Unknown manifest format '.xml', use .csv, .jsonl or .ndjson")
+	})
+}
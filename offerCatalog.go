@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type OfferCatalog struct {
+	Offers   []Offer        `json:"offers" yaml:"offers"`
+	Stacking StackingPolicy `json:"stacking" yaml:"stacking"`
+}
+
+// How the offers of a package are combined when more than one of them applies
+type StackingPolicy struct {
+	Policy     string `json:"policy" yaml:"policy"`         // sum (default), best or compound
+	MaxPercent int    `json:"maxPercent" yaml:"maxPercent"` // The max total discount in percent of the delivery cost, 0 means no limit
+	MaxAmount  Money  `json:"maxAmount" yaml:"maxAmount"`   // The max total discount amount, 0 means no limit
+}
+
+const (
+	StackingSum      = "sum"      // Add up the discount of every offer
+	StackingBest     = "best"     // Apply only the offer with the biggest discount
+	StackingCompound = "compound" // Apply the offers one after another, each one on the cost left by the previous ones
+)
+
+// A single problem found in the catalog file, with the line it was found on
+type OfferCatalogIssue struct {
+	Line    int
+	Message string
+}
+
+// All problems found while loading an offer catalog
+type OfferCatalogError struct {
+	Path   string
+	Issues []OfferCatalogIssue
+}
+
+func (e *OfferCatalogError) Error() string {
+	messages := []string{}
+	for _, issue := range e.Issues {
+		messages = append(messages, fmt.Sprintf("load offer catalog error: %s:%d: %s", e.Path, issue.Line, issue.Message))
+	}
+	return strings.Join(messages, "\n")
+}
+
+// The catalog used when no offer file is given, the offers of the original problem statement
+func defaultOfferCatalog() OfferCatalog {
+	return OfferCatalog{
+		Offers: []Offer{
+			{
+				Id: "OFR001",
+				Distance: CompareAmount{
+					GreaterThanEqual: 0,
+					LessThanEqual:    199,
+				},
+				Weight: CompareAmount{
+					GreaterThanEqual: 70,
+					LessThanEqual:    200,
+				},
+				Percent: 10,
+			},
+			{
+				Id: "OFR002",
+				Distance: CompareAmount{
+					GreaterThanEqual: 50,
+					LessThanEqual:    150,
+				},
+				Weight: CompareAmount{
+					GreaterThanEqual: 100,
+					LessThanEqual:    250,
+				},
+				Percent: 7,
+			},
+			{
+				Id: "OFR003",
+				Distance: CompareAmount{
+					GreaterThanEqual: 50,
+					LessThanEqual:    250,
+				},
+				Weight: CompareAmount{
+					GreaterThanEqual: 10,
+					LessThanEqual:    150,
+				},
+				Percent: 5,
+			},
+		},
+		Stacking: StackingPolicy{Policy: StackingSum},
+	}
+}
+
+// Function to read an offer catalog file, the format is picked by the file extension (.json, .yaml or .yml)
+func LoadOfferCatalog(path string) (OfferCatalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return OfferCatalog{}, fmt.Errorf("load offer catalog error: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return parseOfferCatalog(path, data, true)
+	case ".yaml", ".yml":
+		return parseOfferCatalog(path, data, false)
+	}
+	return OfferCatalog{}, fmt.Errorf("load offer catalog error: %s: Unknown file format, use .json, .yaml or .yml", path)
+}
+
+// Function to parse and validate an offer catalog
+// JSON is a subset of YAML, so both formats are read through the YAML node tree to keep line numbers for every offer
+func parseOfferCatalog(path string, data []byte, isJSON bool) (OfferCatalog, error) {
+	var catalog OfferCatalog
+
+	if isJSON {
+		var syntaxCheck interface{}
+		if err := json.Unmarshal(data, &syntaxCheck); err != nil {
+			line := 1
+			if syntaxErr, ok := err.(*json.SyntaxError); ok {
+				line = lineOfOffset(data, syntaxErr.Offset)
+			}
+			return catalog, &OfferCatalogError{Path: path, Issues: []OfferCatalogIssue{{Line: line, Message: err.Error()}}}
+		}
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return catalog, fmt.Errorf("load offer catalog error: %s: %w", path, err)
+	}
+	if len(root.Content) == 0 {
+		return catalog, &OfferCatalogError{Path: path, Issues: []OfferCatalogIssue{{Line: 1, Message: "Empty offer catalog"}}}
+	}
+
+	document := root.Content[0]
+	issues := checkKnownKeys(document, "offers", "stacking")
+	if stackingNode := mappingValue(document, "stacking"); stackingNode != nil {
+		issues = append(issues, checkKnownKeys(stackingNode, "policy", "maxPercent", "maxAmount")...)
+	}
+	if len(issues) > 0 {
+		return catalog, &OfferCatalogError{Path: path, Issues: issues}
+	}
+
+	offersNode := mappingValue(document, "offers")
+	if offersNode == nil || offersNode.Kind != yaml.SequenceNode {
+		return catalog, &OfferCatalogError{Path: path, Issues: []OfferCatalogIssue{{Line: document.Line, Message: "\"offers\" should be a list of offers"}}}
+	}
+
+	if stackingNode := mappingValue(document, "stacking"); stackingNode != nil {
+		if err := stackingNode.Decode(&catalog.Stacking); err != nil {
+			issues = append(issues, OfferCatalogIssue{Line: stackingNode.Line, Message: err.Error()})
+		} else {
+			issues = append(issues, validateStackingPolicy(catalog.Stacking, stackingNode.Line)...)
+		}
+	}
+
+	offerLines := []int{}
+	for _, offerNode := range offersNode.Content {
+		nodeIssues := checkKnownKeys(offerNode, "id", "distance", "weight", "type", "percent", "amount", "freeDistance", "tiers", "rule")
+		if tiersNode := mappingValue(offerNode, "tiers"); tiersNode != nil && tiersNode.Kind == yaml.SequenceNode {
+			for _, tierNode := range tiersNode.Content {
+				nodeIssues = append(nodeIssues, checkKnownKeys(tierNode, "upToWeight", "percent")...)
+			}
+		}
+		for _, key := range []string{"distance", "weight"} {
+			if rangeNode := mappingValue(offerNode, key); rangeNode != nil {
+				nodeIssues = append(nodeIssues, checkKnownKeys(rangeNode, "greaterThanEqual", "lessThanEqual")...)
+			}
+		}
+		if ruleNode := mappingValue(offerNode, "rule"); ruleNode != nil {
+			nodeIssues = append(nodeIssues, checkRuleKeys(ruleNode)...)
+		}
+		if len(nodeIssues) > 0 {
+			issues = append(issues, nodeIssues...)
+			continue
+		}
+
+		var offer Offer
+		if err := offerNode.Decode(&offer); err != nil {
+			issues = append(issues, OfferCatalogIssue{Line: offerNode.Line, Message: err.Error()})
+			continue
+		}
+		catalog.Offers = append(catalog.Offers, offer)
+		offerLines = append(offerLines, offerNode.Line)
+	}
+
+	issues = append(issues, validateOffers(catalog.Offers, offerLines)...)
+	if len(issues) > 0 {
+		return OfferCatalog{}, &OfferCatalogError{Path: path, Issues: issues}
+	}
+	return catalog, nil
+}
+
+// Function to validate the offers against the Offer/CompareAmount rules
+// offerLines holds the line number of each offer in the catalog file
+func validateOffers(offers []Offer, offerLines []int) []OfferCatalogIssue {
+	issues := []OfferCatalogIssue{}
+	seenIds := map[string]int{}
+
+	for i, offer := range offers {
+		line := offerLines[i]
+		if offer.Id == "" {
+			issues = append(issues, OfferCatalogIssue{Line: line, Message: "Missing offer id"})
+		} else if firstLine, ok := seenIds[offer.Id]; ok {
+			issues = append(issues, OfferCatalogIssue{Line: line, Message: fmt.Sprintf("Duplicate offer id %s, first defined on line %d", offer.Id, firstLine)})
+		} else {
+			seenIds[offer.Id] = line
+		}
+
+		if offer.Percent < 0 || offer.Percent > 100 {
+			issues = append(issues, OfferCatalogIssue{Line: line, Message: fmt.Sprintf("Offer %s percent should be between 0 and 100", offer.Id)})
+		}
+		for _, message := range validateOfferType(offer) {
+			issues = append(issues, OfferCatalogIssue{Line: line, Message: message})
+		}
+
+		ranges := []struct {
+			name   string
+			amount CompareAmount
+		}{{"distance", offer.Distance}, {"weight", offer.Weight}}
+		for _, r := range ranges {
+			name, amount := r.name, r.amount
+			if amount.GreaterThanEqual < 0 || amount.LessThanEqual < 0 {
+				issues = append(issues, OfferCatalogIssue{Line: line, Message: fmt.Sprintf("Offer %s %s range should not be negative", offer.Id, name)})
+			} else if amount.LessThanEqual != 0 && amount.GreaterThanEqual > amount.LessThanEqual {
+				issues = append(issues, OfferCatalogIssue{Line: line, Message: fmt.Sprintf("Offer %s %s range overlaps itself, greaterThanEqual is bigger than lessThanEqual", offer.Id, name)})
+			}
+		}
+
+		if offer.Rule != nil {
+			for _, message := range validateOfferRule(offer.Id, *offer.Rule) {
+				issues = append(issues, OfferCatalogIssue{Line: line, Message: message})
+			}
+		}
+	}
+
+	return issues
+}
+
+// Function to check an offer has the settings of its type
+func validateOfferType(offer Offer) []string {
+	messages := []string{}
+	switch offer.offerType() {
+	case OfferPercent:
+	case OfferFlat, OfferPerKg:
+		if offer.Amount <= 0 {
+			messages = append(messages, fmt.Sprintf("Offer %s amount should be bigger than 0", offer.Id))
+		}
+		if offer.FreeDistance != 0 {
+			messages = append(messages, fmt.Sprintf("Offer %s freeDistance is only used by the freeDistance offers", offer.Id))
+		}
+	case OfferFreeDistance:
+		if offer.FreeDistance <= 0 {
+			messages = append(messages, fmt.Sprintf("Offer %s freeDistance should be bigger than 0", offer.Id))
+		} else if _, err := parseMeasure(formatMeasure(offer.FreeDistance)); err != nil {
+			messages = append(messages, fmt.Sprintf("Offer %s freeDistance should have at most 3 decimals", offer.Id))
+		}
+		if offer.Amount != 0 {
+			messages = append(messages, fmt.Sprintf("Offer %s amount is not used by the freeDistance offers, set freeDistance", offer.Id))
+		}
+	case OfferTiered:
+		if len(offer.Tiers) == 0 {
+			messages = append(messages, fmt.Sprintf("Offer %s should have tiers", offer.Id))
+		}
+		for i, tier := range offer.Tiers {
+			if tier.Percent < 0 || tier.Percent > 100 {
+				messages = append(messages, fmt.Sprintf("Offer %s tier %d percent should be between 0 and 100", offer.Id, i+1))
+			}
+			if tier.UpToWeight < 0 || (tier.UpToWeight == 0 && i != len(offer.Tiers)-1) ||
+				(i > 0 && tier.UpToWeight != 0 && tier.UpToWeight <= offer.Tiers[i-1].UpToWeight) {
+				messages = append(messages, fmt.Sprintf("Offer %s tier %d upToWeight should be bigger than the one of the tier before, only the last tier may have no limit", offer.Id, i+1))
+			}
+		}
+	default:
+		messages = append(messages, fmt.Sprintf("Offer %s has an unknown type \"%s\", use %s, %s, %s, %s or %s",
+			offer.Id, offer.Type, OfferPercent, OfferFlat, OfferTiered, OfferFreeDistance, OfferPerKg))
+	}
+	return messages
+}
+
+// Function to validate the stacking policy of a catalog, line is the line of the "stacking" object
+func validateStackingPolicy(stacking StackingPolicy, line int) []OfferCatalogIssue {
+	issues := []OfferCatalogIssue{}
+	switch stacking.Policy {
+	case "", StackingSum, StackingBest, StackingCompound:
+	default:
+		issues = append(issues, OfferCatalogIssue{Line: line, Message: fmt.Sprintf("Unknown stacking policy \"%s\", use %s, %s or %s", stacking.Policy, StackingSum, StackingBest, StackingCompound)})
+	}
+	if stacking.MaxPercent < 0 || stacking.MaxPercent > 100 {
+		issues = append(issues, OfferCatalogIssue{Line: line, Message: "Stacking maxPercent should be between 0 and 100"})
+	}
+	if stacking.MaxAmount < 0 {
+		issues = append(issues, OfferCatalogIssue{Line: line, Message: "Stacking maxAmount should not be negative"})
+	}
+	return issues
+}
+
+// Function to report unknown keys of a rule node and of the rules inside it
+func checkRuleKeys(node *yaml.Node) []OfferCatalogIssue {
+	issues := checkKnownKeys(node, "weight", "distance", "cost", "packageCount", "validFrom", "validUntil", "segments", "all", "any", "not")
+	for _, key := range []string{"weight", "distance", "cost", "packageCount"} {
+		if boundsNode := mappingValue(node, key); boundsNode != nil {
+			issues = append(issues, checkKnownKeys(boundsNode, "greaterThan", "greaterThanEqual", "lessThan", "lessThanEqual")...)
+		}
+	}
+	for _, key := range []string{"all", "any"} {
+		if listNode := mappingValue(node, key); listNode != nil {
+			if listNode.Kind != yaml.SequenceNode {
+				issues = append(issues, OfferCatalogIssue{Line: listNode.Line, Message: fmt.Sprintf("\"%s\" should be a list of rules", key)})
+				continue
+			}
+			for _, ruleNode := range listNode.Content {
+				issues = append(issues, checkRuleKeys(ruleNode)...)
+			}
+		}
+	}
+	if notNode := mappingValue(node, "not"); notNode != nil {
+		issues = append(issues, checkRuleKeys(notNode)...)
+	}
+	return issues
+}
+
+// Function to report keys of a mapping node which are not part of the schema
+func checkKnownKeys(node *yaml.Node, knownKeys ...string) []OfferCatalogIssue {
+	if node.Kind != yaml.MappingNode {
+		return []OfferCatalogIssue{{Line: node.Line, Message: fmt.Sprintf("Expected an object with keys %s", strings.Join(knownKeys, ", "))}}
+	}
+
+	issues := []OfferCatalogIssue{}
+	for i := 0; i < len(node.Content); i += 2 {
+		key := node.Content[i]
+		isKnown := false
+		for _, knownKey := range knownKeys {
+			if key.Value == knownKey {
+				isKnown = true
+				break
+			}
+		}
+		if !isKnown {
+			issues = append(issues, OfferCatalogIssue{Line: key.Line, Message: fmt.Sprintf("Unknown field \"%s\"", key.Value)})
+		}
+	}
+	return issues
+}
+
+// Function to find the value node of a key in a mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// Function to convert a byte offset to a 1-based line number
+func lineOfOffset(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOfferCatalog(t *testing.T) {
+	t.Run("return offers of a valid yaml catalog", func(t *testing.T) {
+		data := []byte(`offers:
+  - id: OFR010
+    distance:
+      greaterThanEqual: 0
+      lessThanEqual: 100
+    weight:
+      greaterThanEqual: 10
+      lessThanEqual: 50
+    percent: 15
+`)
+		catalog, err := parseOfferCatalog("offers.yaml", data, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, OfferCatalog{Offers: []Offer{
+			{
+				Id:       "OFR010",
+				Distance: CompareAmount{GreaterThanEqual: 0, LessThanEqual: 100},
+				Weight:   CompareAmount{GreaterThanEqual: 10, LessThanEqual: 50},
+				Percent:  15,
+			},
+		}}, catalog)
+	})
+	t.Run("return offers of a valid json catalog", func(t *testing.T) {
+		data := []byte(`{"offers": [{"id": "OFR010", "weight": {"greaterThanEqual": 10}, "percent": 15}]}`)
+		catalog, err := parseOfferCatalog("offers.json", data, true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, OfferCatalog{Offers: []Offer{
+			{
+				Id:      "OFR010",
+				Weight:  CompareAmount{GreaterThanEqual: 10},
+				Percent: 15,
+			},
+		}}, catalog)
+	})
+	t.Run("return line of the json syntax error", func(t *testing.T) {
+		data := []byte("{\n  \"offers\": [\n    {\"id\": \"OFR010\",}\n  ]\n}")
+		_, err := parseOfferCatalog("offers.json", data, true)
+
+		catalogErr, ok := err.(*OfferCatalogError)
+		assert.True(t, ok)
+		assert.Equal(t, 3, catalogErr.Issues[0].Line)
+	})
+	t.Run("return every invalid offer with its line", func(t *testing.T) {
+		data := []byte(`offers:
+  - id: OFR001
+    percent: 10
+  - id: OFR001
+    percent: 5
+  - id: OFR002
+    percent: -5
+  - id: OFR003
+    weight:
+      greaterThanEqual: 100
+      lessThanEqual: 50
+    percent: 5
+  - id: OFR004
+    discount: 5
+`)
+		catalog, err := parseOfferCatalog("offers.yaml", data, false)
+
+		assert.Equal(t, OfferCatalog{}, catalog)
+		assert.Equal(t, &OfferCatalogError{
+			Path: "offers.yaml",
+			Issues: []OfferCatalogIssue{
+				{Line: 14, Message: "Unknown field \"discount\""},
+				{Line: 4, Message: "Duplicate offer id OFR001, first defined on line 2"},
+				{Line: 6, Message: "Offer OFR002 percent should be between 0 and 100"},
+				{Line: 8, Message: "Offer OFR003 weight range overlaps itself, greaterThanEqual is bigger than lessThanEqual"},
+			},
+		}, err)
+	})
+	t.Run("return the rule of an offer", func(t *testing.T) {
+		data := []byte(`offers:
+  - id: OFR010
+    percent: 15
+    rule:
+      weight:
+        greaterThanEqual: 0
+        lessThan: 50
+      validFrom: "2024-01-01"
+      any:
+        - segments: [vip]
+        - cost:
+            greaterThanEqual: 500
+`)
+		catalog, err := parseOfferCatalog("offers.yaml", data, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &OfferRule{
+			Weight:    &Bounds{GreaterThanEqual: intPointer(0), LessThan: intPointer(50)},
+			ValidFrom: "2024-01-01",
+			Any: []OfferRule{
+				{Segments: []string{"vip"}},
+				{Cost: &Bounds{GreaterThanEqual: intPointer(500)}},
+			},
+		}, catalog.Offers[0].Rule)
+	})
+	t.Run("return unknown fields and invalid conditions of a rule", func(t *testing.T) {
+		data := []byte(`offers:
+  - id: OFR010
+    percent: 15
+    rule:
+      not:
+        segment: vip
+  - id: OFR011
+    percent: 15
+    rule:
+      validUntil: tomorrow
+`)
+		_, err := parseOfferCatalog("offers.yaml", data, false)
+
+		assert.Equal(t, &OfferCatalogError{
+			Path: "offers.yaml",
+			Issues: []OfferCatalogIssue{
+				{Line: 6, Message: "Unknown field \"segment\""},
+				{Line: 7, Message: "Offer OFR011 rule validUntil should be a date like 2006-01-02"},
+			},
+		}, err)
+	})
+	t.Run("return offers of every type", func(t *testing.T) {
+		data := []byte(`offers:
+  - id: FLAT100
+    type: flat
+    amount: 100
+  - id: FREE20KM
+    type: freeDistance
+    freeDistance: 20.5
+  - id: KG2
+    type: perKg
+    amount: 2.25
+  - id: TIERED
+    type: tiered
+    tiers:
+      - upToWeight: 100
+        percent: 5
+      - percent: 8
+`)
+		catalog, err := parseOfferCatalog("offers.yaml", data, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []Offer{
+			{Id: "FLAT100", Type: OfferFlat, Amount: moneyOf(100)},
+			{Id: "FREE20KM", Type: OfferFreeDistance, FreeDistance: 20.5},
+			{Id: "KG2", Type: OfferPerKg, Amount: Money(225)},
+			{Id: "TIERED", Type: OfferTiered, Tiers: []OfferTier{{UpToWeight: 100, Percent: 5}, {Percent: 8}}},
+		}, catalog.Offers)
+	})
+	t.Run("return offers without the settings of their type", func(t *testing.T) {
+		data := []byte(`offers:
+  - id: FLAT
+    type: flat
+  - id: TIERED
+    type: tiered
+    tiers:
+      - percent: 5
+      - upToWeight: 100
+        percent: 108
+  - id: BOGO
+    type: bogo
+`)
+		_, err := parseOfferCatalog("offers.yaml", data, false)
+
+		assert.Equal(t, &OfferCatalogError{
+			Path: "offers.yaml",
+			Issues: []OfferCatalogIssue{
+				{Line: 2, Message: "Offer FLAT amount should be bigger than 0"},
+				{Line: 4, Message: "Offer TIERED tier 1 upToWeight should be bigger than the one of the tier before, only the last tier may have no limit"},
+				{Line: 4, Message: "Offer TIERED tier 2 percent should be between 0 and 100"},
+				{Line: 10, Message: "Offer BOGO has an unknown type \"bogo\", use percent, flat, tiered, freeDistance or perKg"},
+			},
+		}, err)
+	})
+	t.Run("return the amounts with more decimals than money and distances", func(t *testing.T) {
+		data := []byte(`offers:
+  - id: FLAT
+    type: flat
+    amount: 0.005
+  - id: FREE
+    type: freeDistance
+    freeDistance: 2.0005
+  - id: FREEAMOUNT
+    type: freeDistance
+    amount: 20
+`)
+		_, err := parseOfferCatalog("offers.yaml", data, false)
+
+		assert.Equal(t, &OfferCatalogError{
+			Path: "offers.yaml",
+			Issues: []OfferCatalogIssue{
+				{Line: 2, Message: "line 4: '0.005' is not an amount with at most 2 decimals"},
+				{Line: 5, Message: "Offer FREE freeDistance should have at most 3 decimals"},
+				{Line: 8, Message: "Offer FREEAMOUNT freeDistance should be bigger than 0"},
+				{Line: 8, Message: "Offer FREEAMOUNT amount is not used by the freeDistance offers, set freeDistance"},
+			},
+		}, err)
+	})
+	t.Run("return the stacking policy", func(t *testing.T) {
+		data := []byte(`{"offers": [], "stacking": {"policy": "compound", "maxPercent": 15, "maxAmount": 200}}`)
+		catalog, err := parseOfferCatalog("offers.json", data, true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StackingPolicy{Policy: StackingCompound, MaxPercent: 15, MaxAmount: moneyOf(200)}, catalog.Stacking)
+	})
+	t.Run("return every invalid stacking setting", func(t *testing.T) {
+		data := []byte(`offers: []
+stacking:
+  policy: all
+  maxPercent: 120
+  maxAmount: -1
+`)
+		_, err := parseOfferCatalog("offers.yaml", data, false)
+
+		assert.Equal(t, &OfferCatalogError{
+			Path: "offers.yaml",
+			Issues: []OfferCatalogIssue{
+				{Line: 3, Message: "Unknown stacking policy \"all\", use sum, best or compound"},
+				{Line: 3, Message: "Stacking maxPercent should be between 0 and 100"},
+				{Line: 3, Message: "Stacking maxAmount should not be negative"},
+			},
+		}, err)
+	})
+	t.Run("return error if offers is not a list", func(t *testing.T) {
+		_, err := parseOfferCatalog("offers.yaml", []byte("offers: OFR001\n"), false)
+
+		assert.EqualError(t, err, "load offer catalog error: offers.yaml:1: \"offers\" should be a list of offers")
+	})
+}
+
+func TestLoadOfferCatalog(t *testing.T) {
+	t.Run("return error for unknown file extension", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "offers.txt")
+		assert.NoError(t, os.WriteFile(path, []byte("offers: []"), 0o644))
+
+		_, err := LoadOfferCatalog(path)
+
+		assert.Error(t, err)
+	})
+	t.Run("load the example catalog", func(t *testing.T) {
+		catalog, err := LoadOfferCatalog("offers.example.yaml")
+
+		assert.NoError(t, err)
+		assert.Equal(t, defaultOfferCatalog(), catalog)
+	})
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// The layout of the dates of a rule and of the order date
+const ruleDateLayout = "2006-01-02"
+
+// A range of values, every bound is optional and a missing bound is not checked
+// The "Than" bounds are open (the value itself is excluded), the "ThanEqual" bounds are closed
+type Bounds struct {
+	GreaterThan      *int `json:"greaterThan,omitempty" yaml:"greaterThan"`
+	GreaterThanEqual *int `json:"greaterThanEqual,omitempty" yaml:"greaterThanEqual"`
+	LessThan         *int `json:"lessThan,omitempty" yaml:"lessThan"`
+	LessThanEqual    *int `json:"lessThanEqual,omitempty" yaml:"lessThanEqual"`
+}
+
+// The eligibility rule of an offer, every condition which is set must match
+// An empty rule matches every package
+type OfferRule struct {
+	Weight       *Bounds     `json:"weight,omitempty" yaml:"weight"`
+	Distance     *Bounds     `json:"distance,omitempty" yaml:"distance"`
+	Cost         *Bounds     `json:"cost,omitempty" yaml:"cost"`                 // The delivery cost before discounts
+	PackageCount *Bounds     `json:"packageCount,omitempty" yaml:"packageCount"` // The number of packages in the order
+	ValidFrom    string      `json:"validFrom,omitempty" yaml:"validFrom"`       // The first day of the offer, YYYY-MM-DD
+	ValidUntil   string      `json:"validUntil,omitempty" yaml:"validUntil"`     // The last day of the offer, YYYY-MM-DD
+	Segments     []string    `json:"segments,omitempty" yaml:"segments"`         // The customer should have one of these segment tags
+	All          []OfferRule `json:"all,omitempty" yaml:"all"`                   // Every rule should match
+	Any          []OfferRule `json:"any,omitempty" yaml:"any"`                   // At least one rule should match
+	Not          *OfferRule  `json:"not,omitempty" yaml:"not"`                   // The rule should not match
+}
+
+// The facts of a package and its order which the rules are evaluated against
+type RuleContext struct {
+	Package          PackageDetail
+	DeliveryCost     Money
+	PackageCount     int
+	OrderDate        time.Time
+	CustomerSegments []string
+}
+
+// Function to check the value against every bound which is set
+func (b *Bounds) contains(value float64) bool {
+	if b == nil {
+		return true
+	}
+	return (b.GreaterThan == nil || value > float64(*b.GreaterThan)) &&
+		(b.GreaterThanEqual == nil || value >= float64(*b.GreaterThanEqual)) &&
+		(b.LessThan == nil || value < float64(*b.LessThan)) &&
+		(b.LessThanEqual == nil || value <= float64(*b.LessThanEqual))
+}
+
+// Function to evaluate the rule for a package
+func (rule OfferRule) matches(context RuleContext) bool {
+	if !rule.Weight.contains(context.Package.Weight) ||
+		!rule.Distance.contains(context.Package.Distance) ||
+		!rule.Cost.contains(context.DeliveryCost.float()) ||
+		!rule.PackageCount.contains(float64(context.PackageCount)) {
+		return false
+	}
+
+	// The dates have a fixed width layout, so they can be compared as strings
+	orderDate := context.OrderDate.Format(ruleDateLayout)
+	if (rule.ValidFrom != "" && orderDate < rule.ValidFrom) || (rule.ValidUntil != "" && orderDate > rule.ValidUntil) {
+		return false
+	}
+
+	if len(rule.Segments) > 0 && !hasCommonSegment(rule.Segments, context.CustomerSegments) {
+		return false
+	}
+
+	for _, r := range rule.All {
+		if !r.matches(context) {
+			return false
+		}
+	}
+	if len(rule.Any) > 0 {
+		matched := false
+		for _, r := range rule.Any {
+			if r.matches(context) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return rule.Not == nil || !rule.Not.matches(context)
+}
+
+// Function to return the rule of an offer, the weight and distance ranges of the offer are added to its rule
+// A range bound of 0 is not checked, as in the original offers
+func (offer Offer) eligibility() OfferRule {
+	rule := OfferRule{
+		Weight:   offer.Weight.bounds(),
+		Distance: offer.Distance.bounds(),
+	}
+	if offer.Rule != nil {
+		rule.All = []OfferRule{*offer.Rule}
+	}
+	return rule
+}
+
+// Function to convert a CompareAmount to closed bounds, a bound of 0 is left out
+func (amount CompareAmount) bounds() *Bounds {
+	bounds := &Bounds{}
+	if amount.GreaterThanEqual != 0 {
+		value := amount.GreaterThanEqual
+		bounds.GreaterThanEqual = &value
+	}
+	if amount.LessThanEqual != 0 {
+		value := amount.LessThanEqual
+		bounds.LessThanEqual = &value
+	}
+	return bounds
+}
+
+func hasCommonSegment(segments []string, customerSegments []string) bool {
+	for _, segment := range segments {
+		for _, customerSegment := range customerSegments {
+			if segment == customerSegment {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Function to validate a rule and the rules inside it, the messages are about the given offer
+func validateOfferRule(offerId string, rule OfferRule) []string {
+	messages := []string{}
+
+	ranges := []struct {
+		name   string
+		bounds *Bounds
+	}{{"weight", rule.Weight}, {"distance", rule.Distance}, {"cost", rule.Cost}, {"packageCount", rule.PackageCount}}
+	for _, r := range ranges {
+		if r.bounds == nil {
+			continue
+		}
+		if message := r.bounds.check(); message != "" {
+			messages = append(messages, fmt.Sprintf("Offer %s rule %s %s", offerId, r.name, message))
+		}
+	}
+
+	for _, date := range []struct {
+		name  string
+		value string
+	}{{"validFrom", rule.ValidFrom}, {"validUntil", rule.ValidUntil}} {
+		if _, err := time.Parse(ruleDateLayout, date.value); date.value != "" && err != nil {
+			messages = append(messages, fmt.Sprintf("Offer %s rule %s should be a date like 2006-01-02", offerId, date.name))
+		}
+	}
+	if rule.ValidFrom != "" && rule.ValidUntil != "" && rule.ValidFrom > rule.ValidUntil {
+		messages = append(messages, fmt.Sprintf("Offer %s rule validFrom is after validUntil", offerId))
+	}
+
+	for _, r := range rule.All {
+		messages = append(messages, validateOfferRule(offerId, r)...)
+	}
+	for _, r := range rule.Any {
+		messages = append(messages, validateOfferRule(offerId, r)...)
+	}
+	if rule.Not != nil {
+		messages = append(messages, validateOfferRule(offerId, *rule.Not)...)
+	}
+	return messages
+}
+
+// Function to find bounds which can't be used together or which no value can match
+func (b *Bounds) check() string {
+	if b.GreaterThan != nil && b.GreaterThanEqual != nil {
+		return "should not have both greaterThan and greaterThanEqual"
+	}
+	if b.LessThan != nil && b.LessThanEqual != nil {
+		return "should not have both lessThan and lessThanEqual"
+	}
+
+	// The values are decimals, so only equal bounds with an open side or crossed bounds leave nothing in between
+	low, high := b.GreaterThanEqual, b.LessThanEqual
+	isOpen := false
+	if b.GreaterThan != nil {
+		low, isOpen = b.GreaterThan, true
+	}
+	if b.LessThan != nil {
+		high, isOpen = b.LessThan, true
+	}
+	if low != nil && high != nil && (*low > *high || (*low == *high && isOpen)) {
+		return "range is empty, the lower bound is bigger than the upper bound"
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func intPointer(value int) *int {
+	return &value
+}
+
+func TestOfferRuleMatches(t *testing.T) {
+	context := RuleContext{
+		Package:          PackageDetail{Title: "PKG1", Weight: 0, Distance: 100},
+		DeliveryCost:     moneyOf(600),
+		PackageCount:     3,
+		OrderDate:        time.Date(2024, 3, 15, 18, 30, 0, 0, time.UTC),
+		CustomerSegments: []string{"business"},
+	}
+
+	t.Run("match an empty rule", func(t *testing.T) {
+		assert.True(t, OfferRule{}.matches(context))
+	})
+	t.Run("check open and closed bounds", func(t *testing.T) {
+		assert.True(t, OfferRule{Weight: &Bounds{LessThanEqual: intPointer(0)}}.matches(context))
+		assert.False(t, OfferRule{Weight: &Bounds{LessThan: intPointer(0)}}.matches(context))
+		assert.True(t, OfferRule{Distance: &Bounds{GreaterThanEqual: intPointer(100)}}.matches(context))
+		assert.False(t, OfferRule{Distance: &Bounds{GreaterThan: intPointer(100)}}.matches(context))
+	})
+	t.Run("check the delivery cost and package count", func(t *testing.T) {
+		assert.True(t, OfferRule{Cost: &Bounds{GreaterThanEqual: intPointer(500)}, PackageCount: &Bounds{GreaterThanEqual: intPointer(3)}}.matches(context))
+		assert.False(t, OfferRule{Cost: &Bounds{GreaterThanEqual: intPointer(700)}}.matches(context))
+		assert.False(t, OfferRule{PackageCount: &Bounds{GreaterThan: intPointer(3)}}.matches(context))
+	})
+	t.Run("check the validity dates including the first and last day", func(t *testing.T) {
+		assert.True(t, OfferRule{ValidFrom: "2024-03-15", ValidUntil: "2024-03-15"}.matches(context))
+		assert.False(t, OfferRule{ValidFrom: "2024-03-16"}.matches(context))
+		assert.False(t, OfferRule{ValidUntil: "2024-03-14"}.matches(context))
+	})
+	t.Run("check the customer segments", func(t *testing.T) {
+		assert.True(t, OfferRule{Segments: []string{"vip", "business"}}.matches(context))
+		assert.False(t, OfferRule{Segments: []string{"vip"}}.matches(context))
+	})
+	t.Run("combine rules with all, any and not", func(t *testing.T) {
+		vip := OfferRule{Segments: []string{"vip"}}
+		heavy := OfferRule{Cost: &Bounds{GreaterThan: intPointer(500)}}
+
+		assert.True(t, OfferRule{Any: []OfferRule{vip, heavy}}.matches(context))
+		assert.False(t, OfferRule{All: []OfferRule{vip, heavy}}.matches(context))
+		assert.True(t, OfferRule{Not: &vip}.matches(context))
+		assert.False(t, OfferRule{Not: &OfferRule{Any: []OfferRule{vip, heavy}}}.matches(context))
+	})
+	t.Run("keep the 0 is not checked rule of the offer ranges", func(t *testing.T) {
+		offer := Offer{Id: "OFR001", Weight: CompareAmount{GreaterThanEqual: 0, LessThanEqual: 10}, Rule: &OfferRule{Segments: []string{"business"}}}
+
+		assert.True(t, offer.eligibility().matches(context))
+	})
+}
+
+func TestValidateOfferRule(t *testing.T) {
+	t.Run("return every invalid condition, also of the inner rules", func(t *testing.T) {
+		rule := OfferRule{
+			Weight:    &Bounds{GreaterThan: intPointer(1), GreaterThanEqual: intPointer(1)},
+			ValidFrom: "15/03/2024",
+			Any: []OfferRule{
+				{Cost: &Bounds{GreaterThan: intPointer(10), LessThan: intPointer(10)}},
+				{ValidFrom: "2024-03-02", ValidUntil: "2024-03-01"},
+			},
+		}
+
+		assert.Equal(t, []string{
+			"Offer OFR010 rule weight should not have both greaterThan and greaterThanEqual",
+			"Offer OFR010 rule validFrom should be a date like 2006-01-02",
+			"Offer OFR010 rule cost range is empty, the lower bound is bigger than the upper bound",
+			"Offer OFR010 rule validFrom is after validUntil",
+		}, validateOfferRule("OFR010", rule))
+	})
+}
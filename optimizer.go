@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// The settings of the plan optimizer, which improves the greedy plan with simulated annealing
+type OptimizerOptions struct {
+	Enabled bool
+	Seed    int64 // The seed of the random moves, the same seed gives the same plan when the iterations end the search
+	// The search stops after this time even if iterations are left, 0 means no limit
+	// A search stopped by it isn't reproducible, the number of moves tried depends on the speed of the machine
+	TimeLimit  time.Duration
+	Iterations int // The number of moves tried, the temperature goes down with them
+}
+
+// The default number of moves of the optimizer
+const defaultOptimizerIterations = 20000
+
+// The result of the optimizer, compared with the greedy plan it starts from
+type OptimizationReport struct {
+	Seed       int64 `json:"seed"`
+	Iterations int   `json:"iterations"` // The number of moves tried before the iteration or time limit
+	// The time of the last delivery of the greedy plan and of the optimized plan, in the time unit of the result
+	BaselineMakespan float64 `json:"baselineMakespan"`
+	Makespan         float64 `json:"makespan"`
+	Improvement      float64 `json:"improvement"` // How much earlier the last delivery is, in percent of the baseline
+	// The time limit stopped the search before its iterations, so the same seed may give another plan
+	TimeLimited bool `json:"timeLimited,omitempty"`
+}
+
+// The trips of each vehicle by vehicle number - 1, each trip is the list of its packages
+type tripAssignment [][][]PackageDetail
+
+// Function to set the optimizer of the solvers, the time limit is a duration like 500ms
+func setOptimizer(options *SolverOptions, enabled bool, seed int64, timeLimit string, iterations int) error {
+	settings := OptimizerOptions{Enabled: enabled, Seed: seed, Iterations: iterations}
+	if iterations < 0 {
+		return fmt.Errorf("optimizer error: The iterations should not be negative")
+	}
+	if settings.Iterations == 0 {
+		settings.Iterations = defaultOptimizerIterations
+	}
+	if timeLimit != "" {
+		limit, err := time.ParseDuration(timeLimit)
+		if err != nil || limit < 0 {
+			return fmt.Errorf("optimizer error: Time limit '%s' should be a duration like 500ms", timeLimit)
+		}
+		settings.TimeLimit = limit
+	}
+	options.Optimizer = settings
+	return nil
+}
+
+// Function to improve the plan of the shipments with simulated annealing, it returns the plan with the earliest last delivery
+// A move takes a package to another trip or a new trip of any vehicle, swaps two packages of different trips, or
+// swaps two trips of a vehicle. A worse plan is kept with a probability which goes down with the temperature,
+// and the temperature goes down linearly with the iterations. Priorities and deadlines are not kept, the SLA report shows them
+func optimizeShipments(subsets []Subset, fleet []Vehicle, plan planSettings, settings OptimizerOptions) ([]Subset, OptimizationReport) {
+	report := OptimizationReport{Seed: settings.Seed}
+	current := newTripAssignment(subsets, fleet)
+	currentMakespan, currentTotal := planEnergy(subsets, fleet, plan)
+	baselineMakespan := currentMakespan
+	best, bestMakespan, bestTotal := subsets, currentMakespan, currentTotal
+
+	random := rand.New(rand.NewSource(settings.Seed))
+	startTemperature := baselineMakespan / 10
+	start := time.Now()
+	for report.Iterations < settings.Iterations && len(subsets) > 0 {
+		if settings.TimeLimit > 0 && time.Since(start) > settings.TimeLimit {
+			report.TimeLimited = true
+			break
+		}
+		report.Iterations++
+		candidate, ok := current.move(random, fleet)
+		if !ok {
+			continue
+		}
+		candidateSubsets, ok := candidate.subsets(fleet, plan)
+		if !ok {
+			continue
+		}
+
+		makespan, total := planEnergy(candidateSubsets, fleet, plan)
+		temperature := startTemperature*(1-float64(report.Iterations)/float64(settings.Iterations)) + 1e-9
+		delta := (makespan - currentMakespan) + (total-currentTotal)/1000
+		if delta <= 0 || random.Float64() < math.Exp(-delta/temperature) {
+			current, currentMakespan, currentTotal = candidate, makespan, total
+			if makespan < bestMakespan-windowEpsilon || (math.Abs(makespan-bestMakespan) <= windowEpsilon && total < bestTotal-windowEpsilon) {
+				best, bestMakespan, bestTotal = candidateSubsets, makespan, total
+			}
+		}
+	}
+
+	report.BaselineMakespan = plan.TimeModel.present(baselineMakespan)
+	report.Makespan = plan.TimeModel.present(bestMakespan)
+	if baselineMakespan > 0 {
+		report.Improvement = math.Round((baselineMakespan-bestMakespan)/baselineMakespan*10000) / 100
+	}
+	return best, report
+}
+
+// Function to return the time of the last delivery and the sum of the delivery times of a plan
+func planEnergy(subsets []Subset, fleet []Vehicle, plan planSettings) (float64, float64) {
+	makespan, total := 0.0, 0.0
+	for _, subset := range subsets {
+		for _, p := range subset.PackageDetails {
+			deliveryTime := packageDeliveryTime(subset, p, fleet[subset.Vehicle-1], plan.TimeModel)
+			total += deliveryTime
+			if deliveryTime > makespan {
+				makespan = deliveryTime
+			}
+		}
+	}
+	return makespan, total
+}
+
+// Function to group the shipments by vehicle in the order of their trips
+func newTripAssignment(subsets []Subset, fleet []Vehicle) tripAssignment {
+	ordered := append([]Subset{}, subsets...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Trip < ordered[j].Trip
+	})
+	assignment := make(tripAssignment, len(fleet))
+	for _, subset := range ordered {
+		assignment[subset.Vehicle-1] = append(assignment[subset.Vehicle-1], subset.PackageDetails)
+	}
+	return assignment
+}
+
+// Function to plan the trips of the assignment one after the other for each vehicle, false if a trip doesn't fit in the shifts
+// The shipments are in the departure order, the lower vehicle number first on equal times
+func (assignment tripAssignment) subsets(fleet []Vehicle, plan planSettings) ([]Subset, bool) {
+	subsets := []Subset{}
+	for v, trips := range assignment {
+		availableAt := 0.0
+		for t, trip := range trips {
+			subset := Subset{}
+			for i := range trip {
+				subset = addToSubset(subset, trip, i)
+			}
+			subset, tripTime := plan.tripTime(subset, fleet[v])
+			departureTime, ok := plan.Schedule.departure(v, availableAt, tripTime)
+			if !ok {
+				return nil, false
+			}
+			subset.Vehicle = v + 1
+			subset.Trip = t + 1
+			subset.DepartureTime = departureTime
+			availableAt = departureTime + tripTime
+			subset.ReturnTime = availableAt
+			subsets = append(subsets, subset)
+		}
+	}
+	sort.SliceStable(subsets, func(i, j int) bool {
+		return subsets[i].DepartureTime < subsets[j].DepartureTime ||
+			(subsets[i].DepartureTime == subsets[j].DepartureTime && subsets[i].Vehicle < subsets[j].Vehicle)
+	})
+	return subsets, true
+}
+
+// Function to make a random move on a copy of the assignment, false if the move breaks a max carriable weight
+func (assignment tripAssignment) move(random *rand.Rand, fleet []Vehicle) (tripAssignment, bool) {
+	moved := assignment.copy()
+	type position struct{ vehicle, trip, index int }
+	positions := []position{}
+	for v, trips := range moved {
+		for t, trip := range trips {
+			for i := range trip {
+				positions = append(positions, position{v, t, i})
+			}
+		}
+	}
+
+	switch random.Intn(3) {
+	case 0:
+		// Take a package to another trip or a new last trip of a vehicle
+		from := positions[random.Intn(len(positions))]
+		vehicle := random.Intn(len(fleet))
+		trip := random.Intn(len(moved[vehicle]) + 1)
+		if vehicle == from.vehicle && trip == from.trip {
+			return nil, false
+		}
+		p := moved[from.vehicle][from.trip][from.index]
+		if trip == len(moved[vehicle]) {
+			moved[vehicle] = append(moved[vehicle], nil)
+		}
+		moved[vehicle][trip] = append(moved[vehicle][trip], p)
+		source := moved[from.vehicle][from.trip]
+		moved[from.vehicle][from.trip] = append(source[:from.index:from.index], source[from.index+1:]...)
+		if !fitsWeight(moved[vehicle][trip], fleet[vehicle]) {
+			return nil, false
+		}
+	case 1:
+		// Swap two packages of different trips
+		a, b := positions[random.Intn(len(positions))], positions[random.Intn(len(positions))]
+		if a.vehicle == b.vehicle && a.trip == b.trip {
+			return nil, false
+		}
+		tripA, tripB := moved[a.vehicle][a.trip], moved[b.vehicle][b.trip]
+		tripA[a.index], tripB[b.index] = tripB[b.index], tripA[a.index]
+		if !fitsWeight(tripA, fleet[a.vehicle]) || !fitsWeight(tripB, fleet[b.vehicle]) {
+			return nil, false
+		}
+	default:
+		// Swap two trips of a vehicle
+		vehicle := random.Intn(len(fleet))
+		if len(moved[vehicle]) < 2 {
+			return nil, false
+		}
+		a, b := random.Intn(len(moved[vehicle])), random.Intn(len(moved[vehicle]))
+		if a == b {
+			return nil, false
+		}
+		moved[vehicle][a], moved[vehicle][b] = moved[vehicle][b], moved[vehicle][a]
+	}
+	return moved.withoutEmptyTrips(), true
+}
+
+// Function to copy the assignment, the trips don't share their arrays with the original
+func (assignment tripAssignment) copy() tripAssignment {
+	copied := make(tripAssignment, len(assignment))
+	for v, trips := range assignment {
+		copied[v] = make([][]PackageDetail, len(trips))
+		for t, trip := range trips {
+			copied[v][t] = append([]PackageDetail{}, trip...)
+		}
+	}
+	return copied
+}
+
+// Function to drop the trips without packages
+func (assignment tripAssignment) withoutEmptyTrips() tripAssignment {
+	for v, trips := range assignment {
+		kept := trips[:0]
+		for _, trip := range trips {
+			if len(trip) > 0 {
+				kept = append(kept, trip)
+			}
+		}
+		assignment[v] = kept
+	}
+	return assignment
+}
+
+// Function to check the packages of a trip are not heavier than the vehicle can carry
+func fitsWeight(trip []PackageDetail, vehicle Vehicle) bool {
+	total := 0
+	for _, p := range trip {
+		total += milliUnits(p.Weight)
+	}
+	return total <= vehicle.MaxCarriableWeight*measureScale
+}
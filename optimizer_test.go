@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetOptimizer(t *testing.T) {
+	t.Run("use the default iterations and parse the time limit", func(t *testing.T) {
+		options := SolverOptions{}
+		assert.NoError(t, setOptimizer(&options, true, 7, "500ms", 0))
+
+		assert.Equal(t, OptimizerOptions{Enabled: true, Seed: 7, TimeLimit: 500 * time.Millisecond, Iterations: defaultOptimizerIterations}, options.Optimizer)
+	})
+	t.Run("return error for wrong limits", func(t *testing.T) {
+		assert.EqualError(t, setOptimizer(&SolverOptions{}, true, 0, "soon", 0), "optimizer error: Time limit 'soon' should be a duration like 500ms")
+		assert.EqualError(t, setOptimizer(&SolverOptions{}, true, 0, "", -1), "optimizer error: The iterations should not be negative")
+	})
+}
+
+func TestOptimizeShipments(t *testing.T) {
+	firstLineInput := FirstLineInput{BaseCost: moneyOf(100), NumberOfPackages: 5}
+	packageDetails := []PackageDetail{
+		{Index: 0, Title: "PKG1", Weight: 50, Distance: 30, OfferIds: []string{"OFR001"}},
+		{Index: 1, Title: "PKG2", Weight: 75, Distance: 125, OfferIds: []string{"OFFR0008"}},
+		{Index: 2, Title: "PKG3", Weight: 175, Distance: 100, OfferIds: []string{"OFFR003"}},
+		{Index: 3, Title: "PKG4", Weight: 110, Distance: 60, OfferIds: []string{"OFR002"}},
+		{Index: 4, Title: "PKG5", Weight: 155, Distance: 95, OfferIds: []string{"NA"}},
+	}
+	optimize := func(seed int64) Result {
+		options := SolverOptions{Offers: defaultOfferCatalog()}
+		assert.NoError(t, setOptimizer(&options, true, seed, "", 5000))
+		result, err := CalculateDeliveryTime(firstLineInput, packageDetails, [][]string{{"2", "70", "200"}}, options)
+		assert.NoError(t, err)
+		return result
+	}
+
+	t.Run("report the earlier last delivery", func(t *testing.T) {
+		result := optimize(1)
+
+		// Without a time limit the iterations end the search, so the plan doesn't depend on the machine
+		assert.Equal(t, &OptimizationReport{Seed: 1, Iterations: 5000, BaselineMakespan: 4.19, Makespan: 4.12, Improvement: 1.67}, result.Optimization)
+		last := 0.0
+		for _, o := range result.Shipments {
+			if o.DeliveryTime > last {
+				last = o.DeliveryTime
+			}
+		}
+		assert.Equal(t, 4.12, last)
+	})
+	t.Run("return the same plan for the same seed", func(t *testing.T) {
+		assert.Equal(t, optimize(3).Dispatches, optimize(3).Dispatches)
+	})
+	t.Run("keep the trips under the max carriable weight", func(t *testing.T) {
+		result := optimize(5)
+
+		weights := map[[2]int]float64{}
+		for i, o := range result.Shipments {
+			weights[[2]int{o.Vehicle, o.Trip}] += packageDetails[i].Weight
+		}
+		for trip, weight := range weights {
+			assert.LessOrEqual(t, weight, 200.0, trip)
+		}
+	})
+	t.Run("stop at the time limit", func(t *testing.T) {
+		subsets, err := getShipmentSubsets(packageDetails, []Vehicle{{Id: "1", MaxSpeed: 70, MaxCarriableWeight: 200}}, planSettings{TimeModel: defaultTimeModel()})
+		assert.NoError(t, err)
+
+		_, report := optimizeShipments(subsets, []Vehicle{{Id: "1", MaxSpeed: 70, MaxCarriableWeight: 200}}, planSettings{TimeModel: defaultTimeModel()},
+			OptimizerOptions{Enabled: true, TimeLimit: time.Nanosecond, Iterations: 1000000})
+
+		assert.Less(t, report.Iterations, 1000000)
+		assert.True(t, report.TimeLimited)
+	})
+}
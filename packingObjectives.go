@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A strategy which picks the packages of the next shipment, getShipmentSubsets asks it once per trip
+type PackingObjective interface {
+	// Function to pick the packages of the next shipment of the vehicle, the packages are sorted by weight
+	NextShipment(sortedPackages []PackageDetail, vehicle Vehicle, plan planSettings) Subset
+}
+
+// The packing of the problem statement: the max number of packages, then the max total weight, then the min max-distance
+type maxLoadObjective struct{}
+
+// Deliver the nearest packages first, so the average delivery time is low
+type minAverageTimeObjective struct{}
+
+// Put the far packages together, so the long trips are few and the last delivery is early
+type makespanObjective struct{}
+
+// Take the packages which pay the most per hour of the trip
+type revenueObjective struct{}
+
+// Load the vehicles as full as possible, heaviest packages first, so there are few trips
+type minTripsObjective struct{}
+
+// The available objectives by the name used in the --objective flag
+var packingObjectives = map[string]PackingObjective{
+	"max-load":     maxLoadObjective{},
+	"min-avg-time": minAverageTimeObjective{},
+	"makespan":     makespanObjective{},
+	"revenue":      revenueObjective{},
+	"min-trips":    minTripsObjective{},
+}
+
+// The objective of the problem statement
+const defaultPackingObjective = "max-load"
+
+// Function to select the packing objective of the solvers by its name, an empty name is the default objective
+func setPackingObjective(options *SolverOptions, name string) error {
+	if name == "" {
+		name = defaultPackingObjective
+	}
+	objective, ok := packingObjectives[name]
+	if !ok {
+		return fmt.Errorf("packing objective error: '%s' is not a known objective, use one of %s", name, strings.Join(packingObjectiveNames(), ", "))
+	}
+	options.Objective = objective
+	return nil
+}
+
+// Function to list the names of the packing objectives in alphabetical order
+func packingObjectiveNames() []string {
+	names := []string{}
+	for name := range packingObjectives {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Function to return the packing objective of the solvers, the one of the problem statement if none is set
+func (options SolverOptions) activeObjective() PackingObjective {
+	if options.Objective == nil {
+		return packingObjectives[defaultPackingObjective]
+	}
+	return options.Objective
+}
+
+// After the packing time budget the shipments are packed greedily, a shipment being packed when it ends too
+func (maxLoadObjective) NextShipment(sortedPackages []PackageDetail, vehicle Vehicle, plan planSettings) Subset {
+	if isPastDeadline(plan.Deadline) {
+		return greedyShipment(sortedPackages, vehicle.MaxCarriableWeight, findMaxSubsetSize(sortedPackages, vehicle.MaxCarriableWeight))
+	}
+	return packShipment(sortedPackages, vehicle.MaxCarriableWeight, plan.Deadline)
+}
+
+func (minAverageTimeObjective) NextShipment(sortedPackages []PackageDetail, vehicle Vehicle, plan planSettings) Subset {
+	order := packageOrder(sortedPackages, func(a, b PackageDetail) bool { return a.Distance < b.Distance })
+	return fillInOrder(sortedPackages, order, vehicle.MaxCarriableWeight)
+}
+
+func (makespanObjective) NextShipment(sortedPackages []PackageDetail, vehicle Vehicle, plan planSettings) Subset {
+	order := packageOrder(sortedPackages, func(a, b PackageDetail) bool { return a.Distance > b.Distance })
+	return fillInOrder(sortedPackages, order, vehicle.MaxCarriableWeight)
+}
+
+func (minTripsObjective) NextShipment(sortedPackages []PackageDetail, vehicle Vehicle, plan planSettings) Subset {
+	order := packageOrder(sortedPackages, func(a, b PackageDetail) bool { return a.Weight > b.Weight })
+	return fillInOrder(sortedPackages, order, vehicle.MaxCarriableWeight)
+}
+
+// Each distance of a package is tried as the farthest stop of the trip: the packages up to it are taken by their
+// cost per weight unit, and the shipment with the most cost per hour of the trip wins, the nearer one on equal rates
+func (revenueObjective) NextShipment(sortedPackages []PackageDetail, vehicle Vehicle, plan planSettings) Subset {
+	byValue := packageOrder(sortedPackages, func(a, b PackageDetail) bool {
+		return float64(plan.Revenues[a.Index])*b.Weight > float64(plan.Revenues[b.Index])*a.Weight
+	})
+
+	var best Subset
+	bestRate, bestRevenue := -1.0, Money(0)
+	for _, limit := range sortedPackages {
+		order := []int{}
+		for _, i := range byValue {
+			if sortedPackages[i].Distance <= limit.Distance {
+				order = append(order, i)
+			}
+		}
+		subset := fillInOrder(sortedPackages, order, vehicle.MaxCarriableWeight)
+		if len(subset.PackageDetailIndices) == 0 {
+			continue
+		}
+
+		revenue := Money(0)
+		for _, p := range subset.PackageDetails {
+			revenue += plan.Revenues[p.Index]
+		}
+		_, tripTime := plan.tripTime(subset, vehicle)
+		rate := float64(revenue)
+		if tripTime > 0 {
+			rate /= tripTime
+		}
+		if rate > bestRate || (rate == bestRate && subset.MaxDistance < best.MaxDistance) {
+			best, bestRate, bestRevenue = subset, rate, revenue
+		}
+	}
+	if bestRevenue == 0 {
+		// Nothing pays, load the vehicle like the problem statement does
+		return maxLoadObjective{}.NextShipment(sortedPackages, vehicle, plan)
+	}
+	return best
+}
+
+// Function to return the indices of the packages in the given order, the lower index first on ties
+func packageOrder(packages []PackageDetail, less func(a, b PackageDetail) bool) []int {
+	order := make([]int, len(packages))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return less(packages[order[i]], packages[order[j]])
+	})
+	return order
+}
+
+// Function to take the packages in the given order while they fit, and build the subset in the order of the packages
+func fillInOrder(sortedPackages []PackageDetail, order []int, maxCarriableWeight int) Subset {
+	maxWeight := maxCarriableWeight * measureScale
+	isChosen := make([]bool, len(sortedPackages))
+	totalWeight := 0
+	for _, i := range order {
+		if weight := milliUnits(sortedPackages[i].Weight); totalWeight+weight <= maxWeight {
+			isChosen[i] = true
+			totalWeight += weight
+		}
+	}
+	return chosenSubset(sortedPackages, isChosen)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackingObjectives(t *testing.T) {
+	sortedPackages := []PackageDetail{
+		{Index: 0, Title: "PKG1", Weight: 50, Distance: 30},
+		{Index: 1, Title: "PKG2", Weight: 75, Distance: 125},
+		{Index: 3, Title: "PKG4", Weight: 110, Distance: 60},
+		{Index: 4, Title: "PKG5", Weight: 155, Distance: 95},
+		{Index: 2, Title: "PKG3", Weight: 175, Distance: 100},
+	}
+	vehicle := Vehicle{Id: "1", MaxSpeed: 70, MaxCarriableWeight: 200}
+	titles := func(subset Subset) []string {
+		names := []string{}
+		for _, p := range subset.PackageDetails {
+			names = append(names, p.Title)
+		}
+		return names
+	}
+
+	t.Run("pick the packages of the next shipment with each objective", func(t *testing.T) {
+		for name, expected := range map[string][]string{
+			"max-load":     {"PKG2", "PKG4"},
+			"min-avg-time": {"PKG1", "PKG4"},
+			"makespan":     {"PKG2", "PKG4"},
+			"min-trips":    {"PKG3"},
+		} {
+			assert.Equal(t, expected, titles(packingObjectives[name].NextShipment(sortedPackages, vehicle, planSettings{TimeModel: defaultTimeModel()})), name)
+		}
+	})
+	t.Run("take the packages which pay the most per hour of the trip", func(t *testing.T) {
+		plan := planSettings{TimeModel: defaultTimeModel(), Revenues: map[int]Money{
+			0: moneyOf(100), 1: moneyOf(2000), 2: moneyOf(300), 3: moneyOf(150), 4: moneyOf(200),
+		}}
+
+		// PKG1 and PKG4 pay 250 in 1.70 hours, PKG2 and PKG1 pay 2100 in 3.56 hours
+		assert.Equal(t, []string{"PKG1", "PKG2"}, titles(revenueObjective{}.NextShipment(sortedPackages, vehicle, plan)))
+	})
+	t.Run("rate the shipments by the time of their route in the routing mode", func(t *testing.T) {
+		sortedPackages := []PackageDetail{
+			{Index: 0, Title: "PKG1", Weight: 50, Distance: 10, Location: &Point{X: 10}},
+			{Index: 1, Title: "PKG2", Weight: 50, Distance: 10, Location: &Point{X: -10}},
+			{Index: 2, Title: "PKG3", Weight: 50, Distance: 12, Location: &Point{X: 12}},
+		}
+		vehicle := Vehicle{Id: "1", MaxSpeed: 10, MaxCarriableWeight: 100}
+		plan := planSettings{TimeModel: defaultTimeModel(), Revenues: map[int]Money{0: moneyOf(100), 1: moneyOf(100), 2: moneyOf(110)}}
+
+		// Out and back, PKG1 and PKG2 pay 200 in 2 hours and PKG1 and PKG3 pay 210 in 2.4 hours
+		assert.Equal(t, []string{"PKG1", "PKG2"}, titles(revenueObjective{}.NextShipment(sortedPackages, vehicle, plan)))
+		// Along a route PKG1 and PKG2 are on opposite sides and take 4 hours, PKG1 and PKG3 still take 2.4 hours
+		plan.Routing = true
+		assert.Equal(t, []string{"PKG1", "PKG3"}, titles(revenueObjective{}.NextShipment(sortedPackages, vehicle, plan)))
+	})
+}
+
+func TestSetPackingObjective(t *testing.T) {
+	t.Run("select the objective by its name", func(t *testing.T) {
+		options := SolverOptions{}
+		assert.Equal(t, maxLoadObjective{}, options.activeObjective())
+
+		assert.NoError(t, setPackingObjective(&options, "min-trips"))
+		assert.Equal(t, minTripsObjective{}, options.activeObjective())
+	})
+	t.Run("return error for unknown objective", func(t *testing.T) {
+		assert.EqualError(t, setPackingObjective(&SolverOptions{}, "cheapest"),
+			"packing objective error: 'cheapest' is not a known objective, use one of makespan, max-load, min-avg-time, min-trips, revenue")
+	})
+	t.Run("plan the shipments with the objective", func(t *testing.T) {
+		options := SolverOptions{Offers: defaultOfferCatalog()}
+		assert.NoError(t, setPackingObjective(&options, "min-avg-time"))
+		packageDetails := []PackageDetail{
+			{Index: 0, Title: "PKG1", Weight: 50, Distance: 30, OfferIds: []string{"OFR001"}},
+			{Index: 1, Title: "PKG2", Weight: 75, Distance: 125, OfferIds: []string{"OFFR0008"}},
+			{Index: 2, Title: "PKG3", Weight: 175, Distance: 100, OfferIds: []string{"OFFR003"}},
+			{Index: 3, Title: "PKG4", Weight: 110, Distance: 60, OfferIds: []string{"OFR002"}},
+			{Index: 4, Title: "PKG5", Weight: 155, Distance: 95, OfferIds: []string{"NA"}},
+		}
+
+		result, err := CalculateDeliveryTime(FirstLineInput{BaseCost: moneyOf(100), NumberOfPackages: 5}, packageDetails, [][]string{{"2", "70", "200"}}, options)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"PKG1", "PKG4"}, result.Dispatches[0].Packages)
+		assert.Equal(t, 0.42, result.Shipments[0].DeliveryTime)
+	})
+}
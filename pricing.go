@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The named pricing models of a pricing file, Default is the name of the model used when none is selected
+type PricingCatalog struct {
+	Default string         `json:"default" yaml:"default"`
+	Models  []PricingModel `json:"models" yaml:"models"`
+}
+
+// How the delivery cost of a package is calculated before discounts
+type PricingModel struct {
+	Name                 string       `json:"name" yaml:"name"`
+	Currency             string       `json:"currency" yaml:"currency"`                         // ISO 4217 code of the amounts, e.g. EUR, optional
+	Rounding             RoundingMode `json:"rounding" yaml:"rounding"`                         // How amounts are rounded to cents: half-up (default), half-even or floor
+	WeightRate           Money        `json:"weightRate" yaml:"weightRate"`                     // Cost per weight unit, after the weight bands
+	WeightBands          []RateBand   `json:"weightBands" yaml:"weightBands"`                   // Optional rates for the first weight units
+	DistanceRate         Money        `json:"distanceRate" yaml:"distanceRate"`                 // Cost per distance unit, after the distance bands
+	DistanceBands        []RateBand   `json:"distanceBands" yaml:"distanceBands"`               // Optional rates for the first distance units
+	MinimumCharge        Money        `json:"minimumCharge" yaml:"minimumCharge"`               // The min delivery cost of a package
+	FuelSurchargePercent int          `json:"fuelSurchargePercent" yaml:"fuelSurchargePercent"` // Percent added to the base, weight and distance cost
+	VolumetricDivisor    int          `json:"volumetricDivisor" yaml:"volumetricDivisor"`       // Volume per weight unit, 0 means the volume is not charged
+	// The surcharges of the express and same-day packages, a priority without surcharge costs the same as standard
+	PrioritySurcharges []PrioritySurcharge `json:"prioritySurcharges" yaml:"prioritySurcharges"`
+}
+
+// The rate of the units from the end of the band before up to UpTo (0 means no limit, only for the last band)
+type RateBand struct {
+	UpTo int   `json:"upTo" yaml:"upTo"`
+	Rate Money `json:"rate" yaml:"rate"`
+}
+
+// The pricing of the original problem statement
+func defaultPricingModel() PricingModel {
+	return PricingModel{Name: "standard", WeightRate: moneyOf(10), DistanceRate: moneyOf(5), PrioritySurcharges: defaultPrioritySurcharges()}
+}
+
+func defaultPricingCatalog() PricingCatalog {
+	return PricingCatalog{Default: "standard", Models: []PricingModel{defaultPricingModel()}}
+}
+
+// Function to read a pricing file (JSON or YAML) and validate its models
+func LoadPricingCatalog(path string) (PricingCatalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return PricingCatalog{}, fmt.Errorf("load pricing error: %w", err)
+	}
+
+	var catalog PricingCatalog
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&catalog); err != nil {
+		return PricingCatalog{}, fmt.Errorf("load pricing error: %s: %w", path, err)
+	}
+	if err := validatePricingCatalog(&catalog); err != nil {
+		return PricingCatalog{}, fmt.Errorf("load pricing error: %s: %w", path, err)
+	}
+	return catalog, nil
+}
+
+// Function to validate the models of a pricing catalog, the first model is the default if none is set
+func validatePricingCatalog(catalog *PricingCatalog) error {
+	if len(catalog.Models) == 0 {
+		return fmt.Errorf("No pricing models")
+	}
+
+	seenNames := map[string]bool{}
+	for _, model := range catalog.Models {
+		if model.Name == "" {
+			return fmt.Errorf("Missing pricing model name")
+		}
+		if seenNames[model.Name] {
+			return fmt.Errorf("Duplicate pricing model %s", model.Name)
+		}
+		seenNames[model.Name] = true
+
+		if model.WeightRate < 0 || model.DistanceRate < 0 || model.MinimumCharge < 0 || model.FuelSurchargePercent < 0 || model.VolumetricDivisor < 0 {
+			return fmt.Errorf("Pricing model %s should not have negative rates or charges", model.Name)
+		}
+		if !validRoundingMode(model.Rounding) {
+			return fmt.Errorf("Pricing model %s has an unknown rounding \"%s\", use %s, %s or %s", model.Name, model.Rounding, RoundHalfUp, RoundHalfEven, RoundFloor)
+		}
+		if !validCurrencyCode(model.Currency) {
+			return fmt.Errorf("Pricing model %s currency should be a 3 letter code like EUR", model.Name)
+		}
+		if err := checkPrioritySurcharges(model.PrioritySurcharges); err != nil {
+			return fmt.Errorf("Pricing model %s %w", model.Name, err)
+		}
+		for _, bands := range []struct {
+			name  string
+			bands []RateBand
+		}{{"weightBands", model.WeightBands}, {"distanceBands", model.DistanceBands}} {
+			if err := checkRateBands(bands.bands); err != nil {
+				return fmt.Errorf("Pricing model %s %s %w", model.Name, bands.name, err)
+			}
+		}
+	}
+
+	if catalog.Default == "" {
+		catalog.Default = catalog.Models[0].Name
+	}
+	if _, err := catalog.model(catalog.Default); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Function to check the bands are in increasing order and only the last one has no limit
+func checkRateBands(bands []RateBand) error {
+	for i, band := range bands {
+		if band.Rate < 0 {
+			return fmt.Errorf("band %d should not have a negative rate", i+1)
+		}
+		if band.UpTo < 0 || (band.UpTo == 0 && i != len(bands)-1) || (i > 0 && band.UpTo != 0 && band.UpTo <= bands[i-1].UpTo) {
+			return fmt.Errorf("band %d upTo should be bigger than the one of the band before, only the last band may have no limit", i+1)
+		}
+	}
+	return nil
+}
+
+// Function to select the pricing model of the solvers by its name, an empty name is the default model
+func setPricingModel(options *SolverOptions, name string) error {
+	model, err := options.Pricing.model(name)
+	if err != nil {
+		return fmt.Errorf("pricing model error: %w", err)
+	}
+	options.PricingModel = model
+	return nil
+}
+
+// Function to override the rounding mode of the selected pricing model, an empty mode keeps the one of the model
+func setRounding(options *SolverOptions, mode string) error {
+	if mode == "" {
+		return nil
+	}
+	if !validRoundingMode(RoundingMode(mode)) {
+		return fmt.Errorf("rounding error: Unknown rounding \"%s\", use %s, %s or %s", mode, RoundHalfUp, RoundHalfEven, RoundFloor)
+	}
+	model := options.activePricingModel()
+	model.Rounding = RoundingMode(mode)
+	options.PricingModel = model
+	return nil
+}
+
+// Function to return the pricing model of the solvers, the pricing of the problem statement if none is selected
+func (options SolverOptions) activePricingModel() PricingModel {
+	if options.PricingModel.Name == "" {
+		return defaultPricingModel()
+	}
+	return options.PricingModel
+}
+
+// Function to check a currency code has 3 upper case letters, an empty code is allowed
+func validCurrencyCode(code string) bool {
+	if code == "" {
+		return true
+	}
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Function to find a pricing model by its name, an empty name is the default model
+func (catalog PricingCatalog) model(name string) (PricingModel, error) {
+	if name == "" {
+		name = catalog.Default
+	}
+	for _, model := range catalog.Models {
+		if model.Name == name {
+			return model, nil
+		}
+	}
+	return PricingModel{}, fmt.Errorf("Unknown pricing model %s", name)
+}
+
+// Function to calculate the delivery cost of a package before discounts
+// The weight is the bigger one of the real weight and the volumetric weight, and the minimum charge is added last
+// The express and same-day packages pay the surcharge of their priority
+func (model PricingModel) breakdown(baseCost Money, packageDetail PackageDetail) CostBreakdown {
+	weight := packageDetail.Weight
+	if volumetricWeight := float64(model.volumetricWeight(packageDetail)); volumetricWeight > weight {
+		weight = volumetricWeight
+	}
+
+	breakdown := CostBreakdown{
+		BaseCost:     baseCost,
+		WeightCost:   bandedCost(weight, model.WeightBands, model.WeightRate, model.Rounding),
+		DistanceCost: bandedCost(packageDetail.Distance, model.DistanceBands, model.DistanceRate, model.Rounding),
+	}
+	breakdown.FuelSurcharge = (breakdown.BaseCost + breakdown.WeightCost + breakdown.DistanceCost).percent(model.FuelSurchargePercent, model.Rounding)
+	breakdown.PrioritySurcharge = model.prioritySurcharge(packageDetail.Priority, breakdown.BaseCost+breakdown.WeightCost+breakdown.DistanceCost)
+	if total := breakdown.total(); total < model.MinimumCharge {
+		breakdown.MinimumChargeTopUp = model.MinimumCharge - total
+	}
+	return breakdown
+}
+
+// Function to calculate the volumetric weight of a package, rounded up, 0 if it has no dimensions
+func (model PricingModel) volumetricWeight(packageDetail PackageDetail) int {
+	if model.VolumetricDivisor == 0 || packageDetail.Dimensions == nil {
+		return 0
+	}
+	volume := packageDetail.Dimensions.Length * packageDetail.Dimensions.Width * packageDetail.Dimensions.Height
+	return (volume + model.VolumetricDivisor - 1) / model.VolumetricDivisor
+}
+
+// Function to charge every unit with the rate of its band, the units after the last band are charged with rate
+// The cost of all bands is summed exactly and rounded once
+func bandedCost(measure float64, bands []RateBand, rate Money, rounding RoundingMode) Money {
+	units := int64(milliUnits(measure))
+	cost, bandStart := int64(0), int64(0)
+	for _, band := range bands {
+		bandEnd := int64(band.UpTo) * measureScale
+		if band.UpTo == 0 || units <= bandEnd {
+			return Money(divRound(cost+(units-bandStart)*int64(band.Rate), measureScale, rounding))
+		}
+		cost += (bandEnd - bandStart) * int64(band.Rate)
+		bandStart = bandEnd
+	}
+	return Money(divRound(cost+(units-bandStart)*int64(rate), measureScale, rounding))
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPricingModelBreakdown(t *testing.T) {
+	t.Run("return the cost of the problem statement with the default model", func(t *testing.T) {
+		breakdown := defaultPricingModel().breakdown(moneyOf(100), PackageDetail{Weight: 50, Distance: 30})
+
+		assert.Equal(t, CostBreakdown{BaseCost: moneyOf(100), WeightCost: moneyOf(500), DistanceCost: moneyOf(150)}, breakdown)
+		assert.Equal(t, moneyOf(750), breakdown.total())
+	})
+	t.Run("charge every band with its rate", func(t *testing.T) {
+		model := PricingModel{
+			WeightRate:    moneyOf(8),
+			WeightBands:   []RateBand{{UpTo: 20, Rate: moneyOf(12)}},
+			DistanceRate:  moneyOf(4),
+			DistanceBands: []RateBand{{UpTo: 50, Rate: moneyOf(6)}, {UpTo: 200, Rate: moneyOf(5)}},
+		}
+		breakdown := model.breakdown(moneyOf(100), PackageDetail{Weight: 30, Distance: 250})
+
+		// 20*12 + 10*8 and 50*6 + 150*5 + 50*4
+		assert.Equal(t, CostBreakdown{BaseCost: moneyOf(100), WeightCost: moneyOf(320), DistanceCost: moneyOf(1250)}, breakdown)
+	})
+	t.Run("add the fuel surcharge and the minimum charge", func(t *testing.T) {
+		model := PricingModel{WeightRate: moneyOf(10), DistanceRate: moneyOf(5), FuelSurchargePercent: 10, MinimumCharge: moneyOf(300)}
+
+		assert.Equal(t, CostBreakdown{BaseCost: moneyOf(100), WeightCost: moneyOf(500), DistanceCost: moneyOf(150), FuelSurcharge: moneyOf(75)}, model.breakdown(moneyOf(100), PackageDetail{Weight: 50, Distance: 30}))
+		assert.Equal(t, moneyOf(300), model.breakdown(moneyOf(100), PackageDetail{Weight: 5, Distance: 5}).total())
+	})
+	t.Run("charge decimal measures and round the cost once", func(t *testing.T) {
+		model := PricingModel{WeightRate: moneyOf(10), WeightBands: []RateBand{{UpTo: 1, Rate: Money(333)}}, DistanceRate: moneyOf(5)}
+		breakdown := model.breakdown(moneyOf(100), PackageDetail{Weight: 1.5, Distance: 0.125})
+
+		// 1*3.33 + 0.5*10 and 0.125*5 = 0.625, rounded half-up
+		assert.Equal(t, Money(833), breakdown.WeightCost)
+		assert.Equal(t, Money(63), breakdown.DistanceCost)
+		model.Rounding = RoundHalfEven
+		assert.Equal(t, Money(62), model.breakdown(moneyOf(100), PackageDetail{Distance: 0.125}).DistanceCost)
+	})
+	t.Run("charge the volumetric weight of big light packages", func(t *testing.T) {
+		model := PricingModel{WeightRate: moneyOf(10), VolumetricDivisor: 5000}
+
+		assert.Equal(t, moneyOf(130), model.breakdown(moneyOf(0), PackageDetail{Weight: 5, Dimensions: &Dimensions{Length: 50, Width: 40, Height: 31}}).WeightCost)
+		assert.Equal(t, moneyOf(50), model.breakdown(moneyOf(0), PackageDetail{Weight: 5}).WeightCost)
+	})
+	t.Run("add the surcharge of the package priority", func(t *testing.T) {
+		model := defaultPricingModel()
+
+		assert.Equal(t, Money(18750), model.breakdown(moneyOf(100), PackageDetail{Weight: 50, Distance: 30, Priority: PriorityExpress}).PrioritySurcharge)
+		assert.Equal(t, moneyOf(1125), model.breakdown(moneyOf(100), PackageDetail{Weight: 50, Distance: 30, Priority: PrioritySameDay}).total())
+		assert.Equal(t, moneyOf(750), model.breakdown(moneyOf(100), PackageDetail{Weight: 50, Distance: 30, Priority: PriorityStandard}).total())
+	})
+}
+
+func TestLoadPricingCatalog(t *testing.T) {
+	t.Run("load the example pricing", func(t *testing.T) {
+		catalog, err := LoadPricingCatalog("pricing.example.yaml")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "standard", catalog.Default)
+		model, err := catalog.model("north")
+		assert.NoError(t, err)
+		assert.Equal(t, moneyOf(300), model.MinimumCharge)
+		assert.Equal(t, "EUR", model.Currency)
+		assert.Equal(t, RoundHalfEven, model.Rounding)
+		assert.Equal(t, PrioritySurcharge{Priority: PriorityExpress, Amount: moneyOf(50)}, model.PrioritySurcharges[0])
+	})
+	t.Run("use the first model as default", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pricing.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"models": [{"name": "south", "weightRate": 9, "distanceRate": 4}]}`), 0o644))
+
+		catalog, err := LoadPricingCatalog(path)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "south", catalog.Default)
+	})
+	t.Run("return error for invalid models", func(t *testing.T) {
+		for _, test := range []struct {
+			data string
+			err  string
+		}{
+			{`models: []`, "No pricing models"},
+			{"models:\n  - name: a\n  - name: a", "Duplicate pricing model a"},
+			{"models:\n  - name: a\n    weightRate: -1", "Pricing model a should not have negative rates or charges"},
+			{"models:\n  - name: a\n    distanceBands: [{upTo: 50, rate: 6}, {upTo: 40, rate: 5}]",
+				"Pricing model a distanceBands band 2 upTo should be bigger than the one of the band before, only the last band may have no limit"},
+			{"default: b\nmodels:\n  - name: a", "Unknown pricing model b"},
+			{"models:\n  - name: a\n    rounding: up", "Pricing model a has an unknown rounding \"up\", use half-up, half-even or floor"},
+			{"models:\n  - name: a\n    currency: euro", "Pricing model a currency should be a 3 letter code like EUR"},
+			{"models:\n  - name: a\n    prioritySurcharges: [{priority: urgent}]", "Pricing model a Unknown priority 'urgent', use standard, express or same-day"},
+			{"models:\n  - name: a\n    prioritySurcharges: [{priority: express, percent: 5}, {priority: express}]", "Pricing model a Duplicate priority surcharge express"},
+		} {
+			path := filepath.Join(t.TempDir(), "pricing.yaml")
+			assert.NoError(t, os.WriteFile(path, []byte(test.data), 0o644))
+
+			_, err := LoadPricingCatalog(path)
+
+			assert.EqualError(t, err, "load pricing error: "+path+": "+test.err)
+		}
+	})
+}
+
+func TestSetPricingModel(t *testing.T) {
+	t.Run("return error for unknown model", func(t *testing.T) {
+		options := SolverOptions{Pricing: defaultPricingCatalog()}
+
+		assert.EqualError(t, setPricingModel(&options, "mars"), "pricing model error: Unknown pricing model mars")
+	})
+}
+
+func TestSetRounding(t *testing.T) {
+	t.Run("override the rounding of the pricing model", func(t *testing.T) {
+		options := SolverOptions{}
+
+		assert.NoError(t, setRounding(&options, "floor"))
+		assert.Equal(t, RoundFloor, options.activePricingModel().Rounding)
+		assert.Equal(t, "standard", options.activePricingModel().Name)
+	})
+	t.Run("return error for unknown rounding", func(t *testing.T) {
+		options := SolverOptions{}
+
+		assert.EqualError(t, setRounding(&options, "up"), "rounding error: Unknown rounding \"up\", use half-up, half-even or floor")
+	})
+}
@@ -0,0 +1,132 @@
+package main
+
+import "fmt"
+
+// How urgent a package is, the packages of a higher priority are shipped in earlier trips
+type Priority string
+
+const (
+	PriorityStandard Priority = "standard" // The default, no surcharge
+	PriorityExpress  Priority = "express"
+	PrioritySameDay  Priority = "same-day"
+)
+
+// The surcharge of a priority level, a fixed amount plus a percent of the base, weight and distance cost
+type PrioritySurcharge struct {
+	Priority Priority `json:"priority" yaml:"priority"`
+	Amount   Money    `json:"amount" yaml:"amount"`
+	Percent  int      `json:"percent" yaml:"percent"`
+}
+
+// The surcharges of the pricing of the problem statement
+func defaultPrioritySurcharges() []PrioritySurcharge {
+	return []PrioritySurcharge{
+		{Priority: PriorityExpress, Percent: 25},
+		{Priority: PrioritySameDay, Percent: 50},
+	}
+}
+
+// Function to parse a priority level, the names are the ones of the package input
+func parsePriority(name string) (Priority, error) {
+	switch Priority(name) {
+	case PriorityStandard, PriorityExpress, PrioritySameDay:
+		return Priority(name), nil
+	}
+	return "", fmt.Errorf("Unknown priority '%s', use %s, %s or %s", name, PriorityStandard, PriorityExpress, PrioritySameDay)
+}
+
+// Function to return the order of a priority level, an empty priority is standard
+func (priority Priority) rank() int {
+	switch priority {
+	case PriorityExpress:
+		return 1
+	case PrioritySameDay:
+		return 2
+	}
+	return 0
+}
+
+// Function to check the priority surcharges of a pricing model, each priority once and no negative charges
+func checkPrioritySurcharges(surcharges []PrioritySurcharge) error {
+	seen := map[Priority]bool{}
+	for _, surcharge := range surcharges {
+		if _, err := parsePriority(string(surcharge.Priority)); err != nil {
+			return err
+		}
+		if seen[surcharge.Priority] {
+			return fmt.Errorf("Duplicate priority surcharge %s", surcharge.Priority)
+		}
+		seen[surcharge.Priority] = true
+		if surcharge.Amount < 0 || surcharge.Percent < 0 {
+			return fmt.Errorf("Priority surcharge %s should not be negative", surcharge.Priority)
+		}
+	}
+	return nil
+}
+
+// Function to calculate the priority surcharge of a package from its base, weight and distance cost
+func (model PricingModel) prioritySurcharge(priority Priority, cost Money) Money {
+	for _, surcharge := range model.PrioritySurcharges {
+		if surcharge.Priority == priority {
+			return surcharge.Amount + cost.percent(surcharge.Percent, model.Rounding)
+		}
+	}
+	return 0
+}
+
+// Function to pick the next shipment when the packages left have different priorities
+// The shipment is packed from the packages of the highest priority with the packing objective, then the rest of the
+// capacity is filled with the lightest packages of the lower priorities. It returns false if all packages have the same priority
+// The given packages array should be sorted based on the weight
+func priorityShipment(sortedPackages []PackageDetail, vehicle Vehicle, plan planSettings) (Subset, bool) {
+	top := 0
+	for _, p := range sortedPackages {
+		if rank := p.Priority.rank(); rank > top {
+			top = rank
+		}
+	}
+	urgent := []PackageDetail{}
+	indices := []int{}
+	for i, p := range sortedPackages {
+		if p.Priority.rank() == top {
+			urgent = append(urgent, p)
+			indices = append(indices, i)
+		}
+	}
+	if len(urgent) == len(sortedPackages) {
+		return Subset{}, false
+	}
+
+	urgentSubset := plan.objective().NextShipment(urgent, vehicle, plan)
+	isChosen := make([]bool, len(sortedPackages))
+	totalWeight := 0
+	for _, i := range urgentSubset.PackageDetailIndices {
+		isChosen[indices[i]] = true
+		totalWeight += milliUnits(urgent[i].Weight)
+	}
+	return fillShipment(sortedPackages, isChosen, totalWeight, vehicle.MaxCarriableWeight), true
+}
+
+// Function to add the lightest packages which still fit to the chosen ones
+// The given packages array should be sorted based on the weight
+func fillShipment(sortedPackages []PackageDetail, isChosen []bool, totalWeight int, maxCarriableWeight int) Subset {
+	maxWeight := maxCarriableWeight * measureScale
+	for i, p := range sortedPackages {
+		if weight := milliUnits(p.Weight); !isChosen[i] && totalWeight+weight <= maxWeight {
+			isChosen[i] = true
+			totalWeight += weight
+		}
+	}
+	return chosenSubset(sortedPackages, isChosen)
+}
+
+// Function to build the subset of the chosen packages in the order of the packages
+func chosenSubset(sortedPackages []PackageDetail, isChosen []bool) Subset {
+	subset := Subset{}
+	for i := range sortedPackages {
+		if isChosen[i] {
+			subset = addToSubset(subset, sortedPackages, i)
+		}
+	}
+	return subset
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriorityShipment(t *testing.T) {
+	packages := []PackageDetail{
+		{Index: 0, Title: "PKG1", Weight: 50, Distance: 30},
+		{Index: 1, Title: "PKG2", Weight: 75, Distance: 125},
+		{Index: 3, Title: "PKG4", Weight: 110, Distance: 60},
+		{Index: 4, Title: "PKG5", Weight: 155, Distance: 95, Priority: PriorityExpress},
+		{Index: 2, Title: "PKG3", Weight: 175, Distance: 100, Priority: PriorityExpress},
+	}
+
+	t.Run("pack the highest priority first and fill with the lightest other packages", func(t *testing.T) {
+		subset, ok := priorityShipment(packages, Vehicle{MaxSpeed: 70, MaxCarriableWeight: 200}, planSettings{})
+
+		assert.True(t, ok)
+		assert.Equal(t, []int{4}, subset.PackageDetailIndices)
+
+		subset, ok = priorityShipment(packages, Vehicle{MaxSpeed: 70, MaxCarriableWeight: 250}, planSettings{})
+
+		assert.True(t, ok)
+		assert.Equal(t, []int{0, 4}, subset.PackageDetailIndices)
+		assert.Equal(t, 225.0, subset.TotalWeight)
+	})
+	t.Run("use the usual packing when all packages have the same priority", func(t *testing.T) {
+		_, ok := priorityShipment(packages[:3], Vehicle{MaxSpeed: 70, MaxCarriableWeight: 200}, planSettings{})
+
+		assert.False(t, ok)
+	})
+}
+
+func TestPriorityDispatch(t *testing.T) {
+	firstLineInput := FirstLineInput{BaseCost: moneyOf(100), NumberOfPackages: 5}
+	packageDetails := []PackageDetail{
+		{Index: 0, Title: "PKG1", Weight: 50, Distance: 30, OfferIds: []string{"OFR001"}},
+		{Index: 1, Title: "PKG2", Weight: 75, Distance: 125, OfferIds: []string{"OFFR0008"}},
+		{Index: 2, Title: "PKG3", Weight: 175, Distance: 100, OfferIds: []string{"OFFR003"}},
+		{Index: 3, Title: "PKG4", Weight: 110, Distance: 60, OfferIds: []string{"OFR002"}},
+		{Index: 4, Title: "PKG5", Weight: 155, Distance: 95, OfferIds: []string{"NA"}, Priority: PriorityExpress},
+	}
+
+	t.Run("ship the express package in the first trip and charge its surcharge", func(t *testing.T) {
+		result, err := CalculateDeliveryTime(firstLineInput, packageDetails, [][]string{{"2", "70", "200"}}, SolverOptions{Offers: defaultOfferCatalog()})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Shipments[4].Trip)
+		assert.Equal(t, 1.35, result.Shipments[4].DeliveryTime)
+		assert.Equal(t, "2656.25", result.Shipments[4].TotalCost.String())
+		assert.Equal(t, []float64{3.98, 1.78, 4.12, 0.85, 1.35}, []float64{result.Shipments[0].DeliveryTime, result.Shipments[1].DeliveryTime,
+			result.Shipments[2].DeliveryTime, result.Shipments[3].DeliveryTime, result.Shipments[4].DeliveryTime})
+	})
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+type Problem struct {
+	Key   string
+	Title string
+	// The description of the input lines and of the API request, shown by the prompts, the help text and /v1/problems
+	// The parsers don't read it, the tests check its examples with them
+	Schema InputSchema
+	Solver ProblemSolver
+	// Optional check of the extra lines, so they can be rejected while reading the input
+	ValidateExtraLines func([][]string) error
+	// The path of the problem under /v1/estimate/ and the function which solves a request, there is no route without it
+	Route    string
+	Estimate func(EstimateRequest, SolverOptions) (EstimateResponse, error)
+}
+
+// The type of an input field, it tells how the field is written
+type FieldType string
+
+const (
+	FieldText    FieldType = "text"    // A token without spaces
+	FieldInteger FieldType = "integer" // A whole number
+	FieldMoney   FieldType = "money"   // An amount with at most 2 decimals
+	FieldMeasure FieldType = "measure" // A weight or distance with at most 3 decimals
+	FieldList    FieldType = "list"    // Comma separated values
+	FieldOption  FieldType = "option"  // An optional "key=value" token, or the dimensions "LxWxH"
+)
+
+// A field of an input line, the name is the field of the API request
+// Type and Example are the token of the console line, APIType and APIExample the JSON value of the API request
+type InputField struct {
+	Name        string          `json:"name"`
+	Type        FieldType       `json:"type"`
+	Description string          `json:"description"`
+	Example     string          `json:"example"`
+	APIType     string          `json:"apiType"` // string, number, integer, array or object
+	APIExample  json.RawMessage `json:"apiExample"`
+	Optional    bool            `json:"optional,omitempty"`
+}
+
+// The format of an input line, its fields are separated by spaces
+type LineSchema struct {
+	Name   string       `json:"name"` // What the line has, used in the prompts and errors, e.g. "shipment detail"
+	Fields []InputField `json:"fields"`
+	// Other accepted forms of the line with their example, e.g. one token per vehicle
+	Alternatives []string `json:"alternatives,omitempty"`
+	// The field of the API request with the fields of the line, empty for the top level of the request
+	// The package line is an array with one object per package
+	APIObject string `json:"apiObject,omitempty"`
+	// Other fields of the API object which replace the fields of the line, e.g. the vehicles of a fleet
+	APIAlternatives []InputField `json:"apiAlternatives,omitempty"`
+}
+
+// The inputs of a problem: the first line, one package line per package and the extra lines
+type InputSchema struct {
+	FirstLine  LineSchema   `json:"firstLine"`
+	Package    LineSchema   `json:"package"`
+	ExtraLines []LineSchema `json:"extraLines,omitempty"`
+}
+
+// The first line of the delivery problems
+var firstLineSchema = LineSchema{
+	Name: "base cost and number of packages",
+	Fields: []InputField{
+		{Name: "baseCost", Type: FieldMoney, Description: "base delivery cost of every package", Example: "100",
+			APIType: "number", APIExample: json.RawMessage(`100`)},
+		// The API example has the one package of the example request
+		{Name: "numberOfPackages", Type: FieldInteger, Description: "number of package lines which follow", Example: "3",
+			APIType: "integer", APIExample: json.RawMessage(`1`)},
+	},
+}
+
+// The package line of the delivery problems
+var packageLineSchema = LineSchema{
+	Name:      "package",
+	APIObject: "packages",
+	Fields: []InputField{
+		{Name: "title", Type: FieldText, Description: "package id, unique in the input", Example: "PKG1",
+			APIType: "string", APIExample: json.RawMessage(`"PKG1"`)},
+		{Name: "weight", Type: FieldMeasure, Description: "weight in kg, more than 0", Example: "50",
+			APIType: "number", APIExample: json.RawMessage(`50`)},
+		{Name: "distance", Type: FieldMeasure, Description: "distance in km, more than 0", Example: "30",
+			APIType: "number", APIExample: json.RawMessage(`30`)},
+		{Name: "offerIds", Type: FieldList, Description: "offer codes, unknown codes give no discount", Example: "OFR001",
+			APIType: "array", APIExample: json.RawMessage(`["OFR001"]`)},
+		{Name: "dimensions", Type: FieldOption, Description: "size in cm for the volumetric weight, LxWxH", Example: "40x30x20", Optional: true,
+			APIType: "object", APIExample: json.RawMessage(`{"length": 40, "width": 30, "height": 20}`)},
+		{Name: "notBefore", Type: FieldOption, Description: "earliest delivery, after=HOURS or after=RFC3339", Example: "after=1.5", Optional: true,
+			APIType: "string", APIExample: json.RawMessage(`"1.5"`)},
+		{Name: "dueBy", Type: FieldOption, Description: "latest delivery, due=HOURS or due=RFC3339", Example: "due=4", Optional: true,
+			APIType: "string", APIExample: json.RawMessage(`"4"`)},
+		{Name: "priority", Type: FieldOption, Description: "priority=standard, express or same-day", Example: "priority=express", Optional: true,
+			APIType: "string", APIExample: json.RawMessage(`"express"`)},
+		{Name: "location", Type: FieldOption, Description: "destination for the routing mode, at=X,Y", Example: "at=12.5,-3", Optional: true,
+			APIType: "object", APIExample: json.RawMessage(`{"x": 12.5, "y": -3}`)},
+	},
+}
+
+// The registered problems by their key
+var problemRegistry = map[string]Problem{}
+
+// Function to add a problem to the registry, the solver files register their problem in init
+// A key used twice is a programming error, so it panics
+func registerProblem(problem Problem) {
+	if _, ok := problemRegistry[problem.Key]; ok {
+		panic(fmt.Sprintf("problem registry error: Duplicate problem key '%s'", problem.Key))
+	}
+	problemRegistry[problem.Key] = problem
+}
+
+// Function to return the list of problems, ordered by their key with the shorter keys first so "10" comes after "9"
+func listProblems() []Problem {
+	problems := make([]Problem, 0, len(problemRegistry))
+	for _, problem := range problemRegistry {
+		problems = append(problems, problem)
+	}
+	sort.Slice(problems, func(i, j int) bool {
+		a, b := problems[i].Key, problems[j].Key
+		return len(a) < len(b) || (len(a) == len(b) && a < b)
+	})
+	return problems
+}
+
+// Function to write the fields of the line, e.g. "baseCost numberOfPackages"
+// The optional ones are written with their example in brackets, as their token has a key or a form like "[due=4]"
+func (line LineSchema) usage() string {
+	names := make([]string, len(line.Fields))
+	for i, field := range line.Fields {
+		names[i] = field.Name
+		if field.Optional {
+			names[i] = "[" + field.Example + "]"
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// Function to write an example of the line with its required fields
+func (line LineSchema) example() string {
+	examples := []string{}
+	for _, field := range line.Fields {
+		if !field.Optional {
+			examples = append(examples, field.Example)
+		}
+	}
+	return strings.Join(examples, " ")
+}
+
+// Function to build an API request body with the API example of every field, the package fields are in its one package
+func (schema InputSchema) apiExample() json.RawMessage {
+	fieldsOf := func(fields []InputField) map[string]json.RawMessage {
+		object := map[string]json.RawMessage{}
+		for _, field := range fields {
+			object[field.Name] = field.APIExample
+		}
+		return object
+	}
+	request := fieldsOf(schema.FirstLine.Fields)
+	packages, _ := json.Marshal([]map[string]json.RawMessage{fieldsOf(schema.Package.Fields)})
+	request[schema.Package.APIObject] = packages
+	for _, line := range schema.ExtraLines {
+		request[line.APIObject], _ = json.Marshal(fieldsOf(line.Fields))
+	}
+	// The examples are valid JSON, the tests post this body
+	body, _ := json.Marshal(request)
+	return body
+}
+
+// Function to write the line format shown under the console prompts
+func (line LineSchema) hint() string {
+	return fmt.Sprintf("Format: %s, e.g. %s", line.usage(), line.example())
+}
+
+// Function to write the inputs of every problem for the help text
+func writeProblemsHelp(output io.Writer, problems []Problem) {
+	fmt.Fprintln(output, "Problems:")
+	for _, problem := range problems {
+		fmt.Fprintf(output, "  %s. %s (--problem %s", problem.Key, problem.Title, problem.Key)
+		if problem.Estimate != nil {
+			fmt.Fprintf(output, ", POST /v1/estimate/%s", problem.Route)
+		}
+		fmt.Fprintln(output, ")")
+
+		lines := append([]LineSchema{problem.Schema.FirstLine, problem.Schema.Package}, problem.Schema.ExtraLines...)
+		for i, line := range lines {
+			count := "1 line"
+			if i == 1 {
+				count = "numberOfPackages lines"
+			}
+			fmt.Fprintf(output, "    %s, %s: %s\n", line.Name, count, line.usage())
+			for _, field := range line.Fields {
+				fmt.Fprintf(output, "      %-18s %-8s %s, e.g. %s\n", field.Name, field.Type, field.Description, field.Example)
+			}
+			for _, alternative := range line.Alternatives {
+				fmt.Fprintf(output, "      or %s\n", alternative)
+			}
+		}
+	}
+}
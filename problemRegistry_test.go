@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListProblems(t *testing.T) {
+	t.Run("return the registered problems by key", func(t *testing.T) {
+		problems := listProblems()
+
+		assert.Equal(t, 2, len(problems))
+		assert.Equal(t, "Delivery Cost Estimation with Offers", problems[0].Title)
+		assert.Equal(t, "time", problems[1].Route)
+		assert.Equal(t, []LineSchema{shipmentLineSchema}, problems[1].Schema.ExtraLines)
+	})
+	t.Run("put the longer keys after the shorter ones", func(t *testing.T) {
+		registerProblem(Problem{Key: "10", Title: "Test problem"})
+		defer delete(problemRegistry, "10")
+
+		problems := listProblems()
+		assert.Equal(t, "10", problems[2].Key)
+	})
+}
+
+func TestRegisterProblem(t *testing.T) {
+	t.Run("panic for a key used twice", func(t *testing.T) {
+		assert.PanicsWithValue(t, "problem registry error: Duplicate problem key '1'", func() {
+			registerProblem(Problem{Key: "1", Title: "Another problem"})
+		})
+	})
+}
+
+func TestLineSchema(t *testing.T) {
+	t.Run("write the usage and example of a line", func(t *testing.T) {
+		assert.Equal(t, "baseCost numberOfPackages", firstLineSchema.usage())
+		assert.Equal(t, "Format: baseCost numberOfPackages, e.g. 100 3", firstLineSchema.hint())
+		assert.Equal(t, "PKG1 50 30 OFR001", packageLineSchema.example())
+		assert.Equal(t, "title weight distance offerIds [40x30x20] [after=1.5] [due=4] [priority=express] [at=12.5,-3]", packageLineSchema.usage())
+	})
+	t.Run("write an example which the parsers accept", func(t *testing.T) {
+		_, err := parseFirstLineInput(splitInputLine(firstLineSchema.example()))
+		assert.NoError(t, err)
+
+		_, err = parsePackageDetail(splitInputLine(packageLineSchema.example()), 0)
+		assert.NoError(t, err)
+
+		// The usage has the optional tokens, a line with all of them is accepted too
+		tokens := []string{}
+		for _, field := range packageLineSchema.Fields {
+			tokens = append(tokens, field.Example)
+		}
+		_, err = parsePackageDetail(tokens, 0)
+		assert.NoError(t, err)
+
+		assert.NoError(t, checkExtraDetails([][]string{splitInputLine(shipmentLineSchema.example())}))
+	})
+}
+
+func TestWriteProblemsHelp(t *testing.T) {
+	t.Run("describe the lines and fields of every problem", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		writeProblemsHelp(output, listProblems())
+
+		assert.Contains(t, output.String(), "  2. Delivery Time Estimation (--problem 2, POST /v1/estimate/time)\n")
+		assert.Contains(t, output.String(), "    shipment detail, 1 line: numberOfVehicles maxSpeed maxCarriableWeight\n")
+		assert.Contains(t, output.String(), "      maxSpeed           integer  speed of the vehicles in km/h, e.g. 70\n")
+		assert.Contains(t, output.String(), "      or @path of a fleet file, e.g. @fleet.example.yaml\n")
+	})
+}
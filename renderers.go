@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// A function which writes the result of a solver in one output format
+type Renderer func(io.Writer, Result) error
+
+// Settings shared by all output formats
+type RenderOptions struct {
+	DispatchPlan bool      // Add the vehicle and trip of each package and the dispatch plan section
+	TimeModel    TimeModel // The precision of the times, a zero TimeModel writes 2 decimals
+}
+
+// The delivery time shown for a package which no vehicle can carry
+const undeliverable = "UNDELIVERABLE"
+
+type renderFunc func(io.Writer, Result, RenderOptions) error
+
+// The available output formats by the name used in the --output flag
+var renderers = map[string]renderFunc{
+	"text":  renderText,
+	"json":  renderJSON,
+	"csv":   renderCSV,
+	"table": renderTable,
+}
+
+// Function to find a renderer by its name
+func getRenderer(name string, options RenderOptions) (Renderer, error) {
+	render, ok := renderers[name]
+	if !ok {
+		return nil, fmt.Errorf("output format error: '%s' is not a known format, use one of %s", name, strings.Join(rendererNames(), ", "))
+	}
+	return func(w io.Writer, result Result) error {
+		return render(w, result, options)
+	}, nil
+}
+
+// Function to list the names of the renderers in alphabetical order
+func rendererNames() []string {
+	names := []string{}
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Function to write the result in the format of the problem statement, one line per package
+func renderText(w io.Writer, result Result, options RenderOptions) error {
+	for _, o := range result.Costs {
+		if _, err := fmt.Fprintf(w, "%s %s %s\n", o.Title, o.Discount, o.TotalCost); err != nil {
+			return err
+		}
+	}
+	for _, o := range result.Shipments {
+		line := fmt.Sprintf("%s %s %s %s", o.Title, o.Discount, o.TotalCost, options.TimeModel.format(o.DeliveryTime, o.DeliveryAt))
+		if options.DispatchPlan {
+			line += fmt.Sprintf(" %s %d", o.VehicleId, o.Trip)
+		}
+		if o.Undeliverable {
+			line = fmt.Sprintf("%s %s %s %s", o.Title, o.Discount, o.TotalCost, undeliverable)
+			if options.DispatchPlan {
+				line += " - -"
+			}
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	if options.DispatchPlan && len(result.Dispatches) > 0 {
+		fmt.Fprintln(w, "<----------- Dispatch plan ----------->")
+		for _, d := range result.Dispatches {
+			if _, err := fmt.Fprintf(w, "%s %d %s %s %s\n", d.VehicleId, d.Trip, options.TimeModel.format(d.DepartureTime, d.DepartureAt),
+				options.TimeModel.format(d.ReturnTime, d.ReturnAt), strings.Join(d.Packages, ",")); err != nil {
+				return err
+			}
+		}
+	}
+
+	if o := result.Optimization; o != nil {
+		fmt.Fprintln(w, "<----------- Optimization ----------->")
+		stop := ""
+		if o.TimeLimited {
+			stop = ", stopped by the time limit"
+		}
+		if _, err := fmt.Fprintf(w, "makespan %s -> %s, %s%% earlier in %d iterations with seed %d%s\n", options.TimeModel.format(o.BaselineMakespan, ""),
+			options.TimeModel.format(o.Makespan, ""), strconv.FormatFloat(o.Improvement, 'f', 2, 64), o.Iterations, o.Seed, stop); err != nil {
+			return err
+		}
+	}
+	if result.Sla != nil {
+		fmt.Fprintln(w, "<----------- SLA report ----------->")
+		fmt.Fprintf(w, "on time %d of %d\n", result.Sla.OnTime, result.Sla.Checked)
+		for _, row := range slaRows(*result.Sla, options) {
+			if _, err := fmt.Fprintln(w, strings.Join(row, " ")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Function to write the result as an indented JSON document
+func renderJSON(w io.Writer, result Result, options RenderOptions) error {
+	if !options.DispatchPlan {
+		result.Dispatches = nil
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// Function to write the result as CSV with a header line, the dispatch plan and the SLA report follow after empty lines
+func renderCSV(w io.Writer, result Result, options RenderOptions) error {
+	writer := csv.NewWriter(w)
+	for i, table := range resultTables(result, options) {
+		if i > 0 {
+			writer.Flush()
+			fmt.Fprintln(w)
+		}
+		if err := writer.Write(table[0]); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(table[1:]); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Function to write the result as tables with aligned columns
+func renderTable(w io.Writer, result Result, options RenderOptions) error {
+	for i, table := range resultTables(result, options) {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, strings.ToUpper(strings.Join(table[0], "\t")))
+		for _, row := range table[1:] {
+			for j, cell := range row {
+				if cell == "" {
+					row[j] = "-"
+				}
+			}
+			fmt.Fprintln(writer, strings.Join(row, "\t"))
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Function to return the tables of the result, each one starts with its header
+func resultTables(result Result, options RenderOptions) [][][]string {
+	header, rows := resultTable(result, options)
+	tables := [][][]string{append([][]string{header}, rows...)}
+
+	if options.DispatchPlan && len(result.Dispatches) > 0 {
+		dispatchTable := [][]string{{"vehicle", "trip", "departure_time", "return_time", "packages"}}
+		for _, d := range result.Dispatches {
+			dispatchTable = append(dispatchTable, []string{
+				d.VehicleId,
+				strconv.Itoa(d.Trip),
+				options.TimeModel.format(d.DepartureTime, d.DepartureAt),
+				options.TimeModel.format(d.ReturnTime, d.ReturnAt),
+				strings.Join(d.Packages, ","),
+			})
+		}
+		tables = append(tables, dispatchTable)
+	}
+
+	if result.Sla != nil {
+		tables = append(tables, append([][]string{{"title", "sla_status", "deviation"}}, slaRows(*result.Sla, options)...))
+	}
+	return tables
+}
+
+// Function to list the late and early packages of the SLA report as title, status and deviation
+func slaRows(report SlaReport, options RenderOptions) [][]string {
+	rows := [][]string{}
+	for _, p := range report.Late {
+		deviation := options.TimeModel.format(p.Deviation, "")
+		if p.Undeliverable {
+			deviation = undeliverable
+		}
+		rows = append(rows, []string{p.Title, "late", deviation})
+	}
+	for _, p := range report.Early {
+		rows = append(rows, []string{p.Title, "early", options.TimeModel.format(p.Deviation, "")})
+	}
+	return rows
+}
+
+// Function to flatten the result into a header and rows of cells
+func resultTable(result Result, options RenderOptions) ([]string, [][]string) {
+	if result.Shipments == nil {
+		header := []string{"title", "discount", "total_cost", "applied_offers", "offer_discounts"}
+		rows := [][]string{}
+		for _, o := range result.Costs {
+			rows = append(rows, costCells(o))
+		}
+		return header, rows
+	}
+
+	header := []string{"title", "discount", "total_cost", "applied_offers", "offer_discounts", "delivery_time", "vehicle", "trip"}
+	rows := [][]string{}
+	for _, o := range result.Shipments {
+		if o.Undeliverable {
+			rows = append(rows, append(costCells(o.CalculationOutput), undeliverable, "", ""))
+			continue
+		}
+		rows = append(rows, append(costCells(o.CalculationOutput),
+			options.TimeModel.format(o.DeliveryTime, o.DeliveryAt),
+			o.VehicleId,
+			strconv.Itoa(o.Trip),
+		))
+	}
+	return header, rows
+}
+
+// Function to format the cost columns of a package, the discount of each offer is written as "id:discount"
+func costCells(o CalculationOutput) []string {
+	offerDiscounts := []string{}
+	for _, appliedOffer := range o.AppliedOffers {
+		offerDiscounts = append(offerDiscounts, fmt.Sprintf("%s:%s", appliedOffer.Id, appliedOffer.Discount))
+	}
+	return []string{o.Title, o.Discount.String(), o.TotalCost.String(), strings.Join(o.AppliedOfferIds, ","), strings.Join(offerDiscounts, ",")}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderers(t *testing.T) {
+	costResult := Result{Costs: []CalculationOutput{
+		{Title: "PKG1", Discount: moneyOf(0), TotalCost: moneyOf(175), AppliedOfferIds: []string{}},
+		{Title: "PKG3", Discount: moneyOf(35), TotalCost: moneyOf(665), AppliedOfferIds: []string{"OFR003"}, AppliedOffers: []AppliedOffer{{Id: "OFR003", Type: OfferPercent, Discount: moneyOf(35)}}},
+	}}
+	timeResult := Result{Shipments: []ShipmentDetail{
+		{CalculationOutput: CalculationOutput{Title: "PKG1", Discount: moneyOf(0), TotalCost: moneyOf(750), AppliedOfferIds: []string{}}, DeliveryTime: 3.98, Vehicle: 1, VehicleId: "1", Trip: 2},
+		{CalculationOutput: CalculationOutput{Title: "PKG4", Discount: moneyOf(105), TotalCost: moneyOf(1395), AppliedOfferIds: []string{"OFR002"}}, DeliveryTime: 0.85, Vehicle: 1, VehicleId: "1", Trip: 1},
+	}}
+
+	render := func(renderer renderFunc, result Result) string {
+		buffer := &bytes.Buffer{}
+		assert.NoError(t, renderer(buffer, result, RenderOptions{}))
+		return buffer.String()
+	}
+
+	t.Run("render the problem statement format", func(t *testing.T) {
+		assert.Equal(t, "PKG1 0 175\nPKG3 35 665\n", render(renderText, costResult))
+		assert.Equal(t, "PKG1 0 750 3.98\nPKG4 105 1395 0.85\n", render(renderText, timeResult))
+	})
+	t.Run("render csv with a header", func(t *testing.T) {
+		assert.Equal(t, "title,discount,total_cost,applied_offers,offer_discounts\nPKG1,0,175,,\nPKG3,35,665,OFR003,OFR003:35\n", render(renderCSV, costResult))
+		assert.Equal(t, "title,discount,total_cost,applied_offers,offer_discounts,delivery_time,vehicle,trip\nPKG1,0,750,,,3.98,1,2\nPKG4,105,1395,OFR002,,0.85,1,1\n", render(renderCSV, timeResult))
+	})
+	t.Run("render an aligned table", func(t *testing.T) {
+		assert.Equal(t, "TITLE  DISCOUNT  TOTAL_COST  APPLIED_OFFERS  OFFER_DISCOUNTS\nPKG1   0         175         -               -\nPKG3   35        665         OFR003          OFR003:35\n", render(renderTable, costResult))
+	})
+	t.Run("render json", func(t *testing.T) {
+		assert.JSONEq(t, `{"costs": [
+			{"title": "PKG1", "discount": 0, "totalCost": 175, "appliedOfferIds": []},
+			{"title": "PKG3", "discount": 35, "totalCost": 665, "appliedOfferIds": ["OFR003"],
+				"appliedOffers": [{"id": "OFR003", "type": "percent", "discount": 35}]}
+		]}`, render(renderJSON, costResult))
+	})
+}
+
+func TestRenderDispatchPlan(t *testing.T) {
+	result := Result{
+		Shipments: []ShipmentDetail{
+			{CalculationOutput: CalculationOutput{Title: "PKG1", Discount: moneyOf(0), TotalCost: moneyOf(750), AppliedOfferIds: []string{}}, DeliveryTime: 3.98, Vehicle: 1, VehicleId: "1", Trip: 2},
+			{CalculationOutput: CalculationOutput{Title: "PKG4", Discount: moneyOf(105), TotalCost: moneyOf(1395), AppliedOfferIds: []string{"OFR002"}}, DeliveryTime: 0.85, Vehicle: 1, VehicleId: "1", Trip: 1},
+		},
+		Dispatches: []Dispatch{
+			{Vehicle: 1, VehicleId: "1", Trip: 1, DepartureTime: 0, ReturnTime: 1.7, Packages: []string{"PKG4"}},
+			{Vehicle: 1, VehicleId: "1", Trip: 2, DepartureTime: 1.7, ReturnTime: 6.26, Packages: []string{"PKG1"}},
+		},
+	}
+
+	render := func(name string, dispatchPlan bool) string {
+		renderer, err := getRenderer(name, RenderOptions{DispatchPlan: dispatchPlan})
+		assert.NoError(t, err)
+		buffer := &bytes.Buffer{}
+		assert.NoError(t, renderer(buffer, result))
+		return buffer.String()
+	}
+
+	t.Run("hide the dispatch plan by default", func(t *testing.T) {
+		assert.Equal(t, "PKG1 0 750 3.98\nPKG4 105 1395 0.85\n", render("text", false))
+		assert.NotContains(t, render("json", false), "dispatches")
+	})
+	t.Run("add vehicle, trip and the dispatch plan to the text", func(t *testing.T) {
+		assert.Equal(t, "PKG1 0 750 3.98 1 2\nPKG4 105 1395 0.85 1 1\n"+
+			"<----------- Dispatch plan ----------->\n"+
+			"1 1 0.00 1.70 PKG4\n"+
+			"1 2 1.70 6.26 PKG1\n", render("text", true))
+	})
+	t.Run("add the dispatch plan table to csv", func(t *testing.T) {
+		assert.Equal(t, "title,discount,total_cost,applied_offers,offer_discounts,delivery_time,vehicle,trip\n"+
+			"PKG1,0,750,,,3.98,1,2\n"+
+			"PKG4,105,1395,OFR002,,0.85,1,1\n"+
+			"\n"+
+			"vehicle,trip,departure_time,return_time,packages\n"+
+			"1,1,0.00,1.70,PKG4\n"+
+			"1,2,1.70,6.26,PKG1\n", render("csv", true))
+	})
+	t.Run("add the dispatches to json", func(t *testing.T) {
+		assert.Contains(t, render("json", true), `"departureTime": 1.7`)
+	})
+}
+
+func TestRenderUndeliverable(t *testing.T) {
+	result := Result{
+		Shipments: []ShipmentDetail{
+			{CalculationOutput: CalculationOutput{Title: "PKG1", Discount: moneyOf(0), TotalCost: moneyOf(750), AppliedOfferIds: []string{}}, DeliveryTime: 0.42, Vehicle: 1, VehicleId: "1", Trip: 1},
+			{CalculationOutput: CalculationOutput{Title: "PKG2", Discount: moneyOf(0), TotalCost: moneyOf(3225), AppliedOfferIds: []string{}}, Undeliverable: true},
+		},
+	}
+
+	render := func(name string, dispatchPlan bool) string {
+		renderer, err := getRenderer(name, RenderOptions{DispatchPlan: dispatchPlan})
+		assert.NoError(t, err)
+		buffer := &bytes.Buffer{}
+		assert.NoError(t, renderer(buffer, result))
+		return buffer.String()
+	}
+
+	t.Run("render undeliverable instead of the delivery time", func(t *testing.T) {
+		assert.Equal(t, "PKG1 0 750 0.42\nPKG2 0 3225 UNDELIVERABLE\n", render("text", false))
+		assert.Equal(t, "PKG1 0 750 0.42 1 1\nPKG2 0 3225 UNDELIVERABLE - -\n", render("text", true))
+	})
+	t.Run("leave the vehicle and trip of an undeliverable package empty", func(t *testing.T) {
+		assert.Equal(t, "title,discount,total_cost,applied_offers,offer_discounts,delivery_time,vehicle,trip\n"+
+			"PKG1,0,750,,,0.42,1,1\n"+
+			"PKG2,0,3225,,,UNDELIVERABLE,,\n", render("csv", false))
+	})
+}
+
+func TestRenderTimeModel(t *testing.T) {
+	result := Result{Shipments: []ShipmentDetail{
+		{CalculationOutput: CalculationOutput{Title: "PKG1", Discount: moneyOf(0), TotalCost: moneyOf(750), AppliedOfferIds: []string{}}, DeliveryTime: 3.985, Vehicle: 1, VehicleId: "1", Trip: 2},
+		{CalculationOutput: CalculationOutput{Title: "PKG4", Discount: moneyOf(105), TotalCost: moneyOf(1395), AppliedOfferIds: []string{"OFR002"}}, DeliveryTime: 0.85, DeliveryAt: "2024-03-15T08:51:00Z", Vehicle: 1, VehicleId: "1", Trip: 1},
+	}}
+
+	t.Run("write the times with the precision of the time model or their wall-clock time", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		assert.NoError(t, renderText(buffer, result, RenderOptions{TimeModel: TimeModel{Precision: 3, Rounding: TimeRound}}))
+
+		assert.Equal(t, "PKG1 0 750 3.985\nPKG4 105 1395 2024-03-15T08:51:00Z\n", buffer.String())
+	})
+}
+
+func TestRenderSla(t *testing.T) {
+	result := Result{
+		Shipments: []ShipmentDetail{
+			{CalculationOutput: CalculationOutput{Title: "PKG1", Discount: moneyOf(0), TotalCost: moneyOf(750), AppliedOfferIds: []string{}}, DeliveryTime: 3.98, Vehicle: 1, VehicleId: "1", Trip: 2},
+		},
+		Sla: &SlaReport{Checked: 3, OnTime: 1, Late: []SlaPackage{{Title: "PKG1", Deviation: 2.98}, {Title: "PKG2", Undeliverable: true}},
+			Early: []SlaPackage{{Title: "PKG3", Deviation: 0.5}}},
+	}
+
+	t.Run("write the late and early packages after the shipments", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		assert.NoError(t, renderText(buffer, result, RenderOptions{}))
+
+		assert.Equal(t, "PKG1 0 750 3.98\n<----------- SLA report ----------->\non time 1 of 3\n"+
+			"PKG1 late 2.98\nPKG2 late UNDELIVERABLE\nPKG3 early 0.50\n", buffer.String())
+	})
+	t.Run("write the SLA report as its own table", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		assert.NoError(t, renderCSV(buffer, result, RenderOptions{}))
+
+		assert.Equal(t, "title,discount,total_cost,applied_offers,offer_discounts,delivery_time,vehicle,trip\n"+
+			"PKG1,0,750,,,3.98,1,2\n\n"+
+			"title,sla_status,deviation\nPKG1,late,2.98\nPKG2,late,UNDELIVERABLE\nPKG3,early,0.50\n", buffer.String())
+	})
+}
+
+func TestRenderOptimization(t *testing.T) {
+	t.Run("write the improvement of the optimizer", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		assert.NoError(t, renderText(buffer, Result{Optimization: &OptimizationReport{Seed: 1, Iterations: 5000, BaselineMakespan: 4.19, Makespan: 4.12, Improvement: 1.67}}, RenderOptions{}))
+
+		assert.Equal(t, "<----------- Optimization ----------->\nmakespan 4.19 -> 4.12, 1.67% earlier in 5000 iterations with seed 1\n", buffer.String())
+	})
+	t.Run("write that the time limit stopped the optimizer", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		assert.NoError(t, renderText(buffer, Result{Optimization: &OptimizationReport{Seed: 1, Iterations: 120, BaselineMakespan: 4.19, Makespan: 4.19, TimeLimited: true}}, RenderOptions{}))
+
+		assert.Equal(t, "<----------- Optimization ----------->\nmakespan 4.19 -> 4.19, 0.00% earlier in 120 iterations with seed 1, stopped by the time limit\n", buffer.String())
+	})
+}
+
+func TestGetRenderer(t *testing.T) {
+	t.Run("return error for unknown format", func(t *testing.T) {
+		_, err := getRenderer("xml", RenderOptions{})
+
+		assert.EqualError(t, err, "output format error: 'xml' is not a known format, use one of csv, json, table, text")
+	})
+}
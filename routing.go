@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// A position on the map, in distance units from the depot which is at 0,0
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Function to parse a location "X,Y", both with at most 3 decimals
+func parsePoint(input string) (Point, error) {
+	x, y, found := strings.Cut(input, ",")
+	xValue, xErr := parseMeasure(x)
+	yValue, yErr := parseMeasure(y)
+	if !found || xErr != nil || yErr != nil {
+		return Point{}, fmt.Errorf("'%s' should be a location like 12.5,-3", input)
+	}
+	return Point{X: xValue, Y: yValue}, nil
+}
+
+// Function to return the straight line distance between two points
+func (p Point) distance(other Point) float64 {
+	return math.Hypot(p.X-other.X, p.Y-other.Y)
+}
+
+// Function to check every package has a location for the routing mode
+func checkLocations(packages []PackageDetail) error {
+	for _, p := range packages {
+		if p.Location == nil {
+			return fmt.Errorf("Validate package error: Routing needs the location of package %s", p.Title)
+		}
+	}
+	return nil
+}
+
+// Function to return the distance from the depot to a package, the distance to its location in the routing mode
+func (plan planSettings) depotDistance(p PackageDetail) float64 {
+	if plan.Routing && p.Location != nil {
+		return Point{}.distance(*p.Location)
+	}
+	return p.Distance
+}
+
+// Function to order the stops of a shipment and set the distance driven to each stop and the length of the round trip
+// The route starts with the nearest neighbor from the depot and is improved with 2-opt moves until none is shorter
+func routeSubset(subset Subset) Subset {
+	points := make([]Point, len(subset.PackageDetails))
+	for i, p := range subset.PackageDetails {
+		points[i] = *p.Location
+	}
+	route := twoOpt(nearestNeighborRoute(points), points)
+
+	subset.StopDistances = map[int]float64{}
+	driven, at := 0.0, Point{}
+	for _, stop := range route {
+		driven += at.distance(points[stop])
+		at = points[stop]
+		subset.StopDistances[subset.PackageDetails[stop].Index] = driven
+	}
+	subset.RouteLength = driven + at.distance(Point{})
+	return subset
+}
+
+// Function to visit the nearest stop left each time, starting from the depot, the first stop wins on equal distances
+func nearestNeighborRoute(points []Point) []int {
+	route := make([]int, 0, len(points))
+	isVisited := make([]bool, len(points))
+	at := Point{}
+	for len(route) < len(points) {
+		next := -1
+		for i, p := range points {
+			if !isVisited[i] && (next == -1 || at.distance(p) < at.distance(points[next])) {
+				next = i
+			}
+		}
+		isVisited[next] = true
+		route = append(route, next)
+		at = points[next]
+	}
+	return route
+}
+
+// Function to reverse parts of the route while it makes the round trip from the depot shorter
+func twoOpt(route []int, points []Point) []int {
+	// The stop at a position of the round trip, the depot is before the first and after the last stop
+	stop := func(position int) Point {
+		if position < 0 || position >= len(route) {
+			return Point{}
+		}
+		return points[route[position]]
+	}
+
+	for isImproved := true; isImproved; {
+		isImproved = false
+		for i := 0; i < len(route)-1; i++ {
+			for j := i + 1; j < len(route); j++ {
+				// Reversing route[i..j] replaces the edges before i and after j
+				before := stop(i-1).distance(stop(i)) + stop(j).distance(stop(j+1))
+				after := stop(i-1).distance(stop(j)) + stop(i).distance(stop(j+1))
+				if after < before-1e-9 {
+					for a, b := i, j; a < b; a, b = a+1, b-1 {
+						route[a], route[b] = route[b], route[a]
+					}
+					isImproved = true
+				}
+			}
+		}
+	}
+	return route
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePoint(t *testing.T) {
+	t.Run("return the location", func(t *testing.T) {
+		point, err := parsePoint("12.5,-3")
+
+		assert.NoError(t, err)
+		assert.Equal(t, Point{X: 12.5, Y: -3}, point)
+	})
+	t.Run("return error for other inputs", func(t *testing.T) {
+		for _, input := range []string{"12.5", "1,2,3", "a,1", "1.2345,1"} {
+			_, err := parsePoint(input)
+
+			assert.EqualError(t, err, "'"+input+"' should be a location like 12.5,-3", input)
+		}
+	})
+}
+
+func TestRoute(t *testing.T) {
+	routeLength := func(route []int, points []Point) float64 {
+		length, at := 0.0, Point{}
+		for _, stop := range route {
+			length += at.distance(points[stop])
+			at = points[stop]
+		}
+		return length + at.distance(Point{})
+	}
+
+	t.Run("remove the crossings of the nearest neighbor route", func(t *testing.T) {
+		points := []Point{{X: -2, Y: 2}, {X: 3, Y: 3}, {X: 2, Y: 1}, {X: 5, Y: -3}}
+		route := nearestNeighborRoute(points)
+		assert.Equal(t, []int{2, 1, 0, 3}, route)
+		assert.InDelta(t, 24.004, routeLength(route, points), 0.001)
+
+		route = twoOpt(route, points)
+
+		assert.InDelta(t, 20.994, routeLength(route, points), 0.001)
+		assert.ElementsMatch(t, []int{0, 1, 2, 3}, route)
+	})
+	t.Run("set the distance driven to each stop", func(t *testing.T) {
+		subset := routeSubset(Subset{PackageDetails: []PackageDetail{
+			{Index: 0, Title: "PKG1", Location: &Point{X: 30}},
+			{Index: 1, Title: "PKG2", Location: &Point{Y: 40}},
+			{Index: 2, Title: "PKG3", Location: &Point{X: 30, Y: 40}},
+		}})
+
+		assert.Equal(t, map[int]float64{0: 30, 2: 70, 1: 100}, subset.StopDistances)
+		assert.Equal(t, 140.0, subset.RouteLength)
+	})
+}
+
+func TestRoutingMode(t *testing.T) {
+	firstLineInput := FirstLineInput{BaseCost: moneyOf(100), NumberOfPackages: 3}
+	packageDetails := []PackageDetail{
+		{Index: 0, Title: "PKG1", Weight: 50, Distance: 30, OfferIds: []string{"NA"}, Location: &Point{X: 30}},
+		{Index: 1, Title: "PKG2", Weight: 40, Distance: 40, OfferIds: []string{"NA"}, Location: &Point{Y: 40}},
+		{Index: 2, Title: "PKG3", Weight: 60, Distance: 50, OfferIds: []string{"NA"}, Location: &Point{X: 30, Y: 40}},
+	}
+
+	t.Run("time the deliveries and the return along the route", func(t *testing.T) {
+		result, err := CalculateDeliveryTime(firstLineInput, packageDetails, [][]string{{"1", "70", "200"}}, SolverOptions{Offers: defaultOfferCatalog(), Routing: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []float64{0.42, 1.42, 1.00}, []float64{result.Shipments[0].DeliveryTime, result.Shipments[1].DeliveryTime, result.Shipments[2].DeliveryTime})
+		assert.Equal(t, []string{"PKG1", "PKG3", "PKG2"}, result.Dispatches[0].Packages)
+		assert.Equal(t, 2.0, result.Dispatches[0].ReturnTime)
+		assert.Equal(t, 140.0, result.Dispatches[0].RouteLength)
+	})
+	t.Run("return error for a package without location", func(t *testing.T) {
+		withoutLocation := append([]PackageDetail{}, packageDetails...)
+		withoutLocation[1].Location = nil
+		_, err := CalculateDeliveryTime(firstLineInput, withoutLocation, [][]string{{"1", "70", "200"}}, SolverOptions{Offers: defaultOfferCatalog(), Routing: true})
+
+		assert.EqualError(t, err, "Validate package error: Routing needs the location of package PKG2")
+	})
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// A daily working window of a vehicle, in minutes since midnight
+type shift struct {
+	Start int
+	End   int
+}
+
+// The working hours of the vehicles, a trip is only planned if it fits in one shift
+// A zero Schedule has no limits, every trip leaves as soon as its vehicle is back
+type Schedule struct {
+	Start       time.Time             // The wall-clock time of hour 0
+	Shifts      [][]shift             // The shifts of each vehicle by vehicle number - 1, no shifts means no limits
+	WorkingDays map[time.Weekday]bool // The days with shifts, empty means every day
+}
+
+// The names of the working days in the --working-days flag
+var weekdayNames = map[string]time.Weekday{
+	"mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday, "thu": time.Thursday,
+	"fri": time.Friday, "sat": time.Saturday, "sun": time.Sunday,
+}
+
+// The max number of days a trip is postponed to find a shift which is long enough
+const maxScheduleDays = 366
+
+// Function to set the default shifts of the vehicles and the working days
+// Shifts are "HH:MM-HH:MM" windows, the days are short names like mon or fri
+func setSchedule(options *SolverOptions, shifts []string, workingDays []string) error {
+	if _, err := parseShifts(shifts); err != nil {
+		return fmt.Errorf("schedule error: %w", err)
+	}
+	days := []time.Weekday{}
+	for _, name := range workingDays {
+		day, ok := weekdayNames[strings.ToLower(name)]
+		if !ok {
+			return fmt.Errorf("schedule error: Unknown working day '%s', use mon, tue, wed, thu, fri, sat or sun", name)
+		}
+		days = append(days, day)
+	}
+	options.Shifts = shifts
+	options.WorkingDays = days
+	return nil
+}
+
+// Function to parse "HH:MM-HH:MM" windows, they should be in increasing order and not overlap
+func parseShifts(windows []string) ([]shift, error) {
+	shifts := []shift{}
+	for _, window := range windows {
+		start, end, found := strings.Cut(window, "-")
+		startMinute, startErr := parseClockMinute(start)
+		endMinute, endErr := parseClockMinute(end)
+		if !found || startErr != nil || endErr != nil || endMinute <= startMinute ||
+			(len(shifts) > 0 && startMinute < shifts[len(shifts)-1].End) {
+			return nil, fmt.Errorf("Wrong shift '%s', use HH:MM-HH:MM windows in increasing order", window)
+		}
+		shifts = append(shifts, shift{Start: startMinute, End: endMinute})
+	}
+	return shifts, nil
+}
+
+// Function to parse "HH:MM" to minutes since midnight, "24:00" is the end of the day
+func parseClockMinute(clock string) (int, error) {
+	if clock == "24:00" {
+		return 24 * 60, nil
+	}
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, err
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// Function to build the schedule of the fleet, vehicles without own shifts use the default shifts of the options
+// It's a zero Schedule if no vehicle has shifts
+func newSchedule(fleet []Vehicle, options SolverOptions) (Schedule, error) {
+	defaultShifts, err := parseShifts(options.Shifts)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("Validate schedule error: %w", err)
+	}
+
+	schedule := Schedule{Shifts: make([][]shift, len(fleet)), WorkingDays: map[time.Weekday]bool{}}
+	hasShifts := false
+	for i, vehicle := range fleet {
+		schedule.Shifts[i] = defaultShifts
+		if len(vehicle.Shifts) > 0 {
+			if schedule.Shifts[i], err = parseShifts(vehicle.Shifts); err != nil {
+				return Schedule{}, fmt.Errorf("Validate schedule error: %s of vehicle %s", err, vehicle.Id)
+			}
+		}
+		hasShifts = hasShifts || len(schedule.Shifts[i]) > 0
+	}
+	if !hasShifts {
+		return Schedule{}, nil
+	}
+
+	schedule.Start = options.activeTimeModel().DispatchStart
+	if schedule.Start.IsZero() {
+		return Schedule{}, fmt.Errorf("%s", "Validate schedule error: Shifts need a dispatch start")
+	}
+	for _, day := range options.WorkingDays {
+		schedule.WorkingDays[day] = true
+	}
+	return schedule, nil
+}
+
+// Function to find the first time from the given one, in hours since the start, when the vehicle can make a trip
+// of the given hours inside one of its shifts, false if the trip is longer than every shift
+func (schedule Schedule) departure(vehicle int, from float64, duration float64) (float64, bool) {
+	if schedule.Start.IsZero() || len(schedule.Shifts[vehicle]) == 0 {
+		return from, true
+	}
+
+	earliest := schedule.Start.Add(hoursDuration(from))
+	tripDuration := hoursDuration(duration)
+	year, month, day := earliest.Date()
+	for d := 0; d <= maxScheduleDays; d++ {
+		date := time.Date(year, month, day+d, 0, 0, 0, 0, earliest.Location())
+		if len(schedule.WorkingDays) > 0 && !schedule.WorkingDays[date.Weekday()] {
+			continue
+		}
+		for _, s := range schedule.Shifts[vehicle] {
+			begin := time.Date(year, month, day+d, 0, s.Start, 0, 0, earliest.Location())
+			end := time.Date(year, month, day+d, 0, s.End, 0, 0, earliest.Location())
+			if begin.Before(earliest) {
+				begin = earliest
+			}
+			if !begin.Add(tripDuration).After(end) {
+				if begin.Equal(earliest) {
+					// Keep the exact time if the trip isn't postponed
+					return from, true
+				}
+				return begin.Sub(schedule.Start).Hours(), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// Function to convert hours to a duration, rounded to seconds
+func hoursDuration(hours float64) time.Duration {
+	return time.Duration(math.Round(hours*3600)) * time.Second
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule(t *testing.T) {
+	firstLineInput := FirstLineInput{BaseCost: moneyOf(100), NumberOfPackages: 5}
+	packageDetails := []PackageDetail{
+		{Index: 0, Title: "PKG1", Weight: 50, Distance: 30, OfferIds: []string{"NA"}},
+		{Index: 1, Title: "PKG2", Weight: 75, Distance: 125, OfferIds: []string{"NA"}},
+		{Index: 2, Title: "PKG3", Weight: 175, Distance: 100, OfferIds: []string{"NA"}},
+		{Index: 3, Title: "PKG4", Weight: 110, Distance: 60, OfferIds: []string{"NA"}},
+		{Index: 4, Title: "PKG5", Weight: 155, Distance: 95, OfferIds: []string{"NA"}},
+	}
+	scheduleOptions := func(shifts []string, workingDays []string) SolverOptions {
+		options := SolverOptions{Offers: defaultOfferCatalog()}
+		assert.NoError(t, setTimeModel(&options, 2, "", false, "", "2024-03-15T08:00:00Z"))
+		assert.NoError(t, setSchedule(&options, shifts, workingDays))
+		return options
+	}
+	etas := func(result Result) []string {
+		times := []string{}
+		for _, o := range result.Shipments {
+			times = append(times, o.DeliveryAt)
+		}
+		return times
+	}
+
+	t.Run("roll the trips which don't fit in the shift to the next day", func(t *testing.T) {
+		result, err := CalculateDeliveryTime(firstLineInput, packageDetails, [][]string{{"2", "70", "200"}}, scheduleOptions([]string{"08:00-12:00"}, nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, TimeClock, result.TimeUnit)
+		assert.Equal(t, []string{
+			"2024-03-16T08:25:12Z",
+			"2024-03-15T09:46:48Z",
+			"2024-03-15T09:25:12Z",
+			"2024-03-15T08:51:00Z",
+			"2024-03-16T09:21:00Z",
+		}, etas(result))
+	})
+	t.Run("skip the days without shifts", func(t *testing.T) {
+		result, err := CalculateDeliveryTime(firstLineInput, packageDetails, [][]string{{"2", "70", "200"}},
+			scheduleOptions([]string{"08:00-12:00"}, []string{"mon", "tue", "wed", "thu", "fri"}))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "2024-03-18T09:21:00Z", result.Shipments[4].DeliveryAt)
+	})
+	t.Run("use the shifts of each vehicle", func(t *testing.T) {
+		fleet := ExtraDetails{Vehicles: []Vehicle{
+			{Id: "EARLY", MaxSpeed: 70, MaxCarriableWeight: 200, Shifts: []string{"08:00-12:00"}},
+			{Id: "LATE", MaxSpeed: 70, MaxCarriableWeight: 200, Shifts: []string{"13:00-18:00"}},
+		}}
+		result, err := estimateDeliveryTime(firstLineInput, packageDetails, fleet, scheduleOptions(nil, nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "2024-03-15T08:00:00Z", result.Dispatches[0].DepartureAt)
+		assert.Equal(t, "EARLY", result.Dispatches[0].VehicleId)
+		assert.Equal(t, "2024-03-15T13:00:00Z", result.Dispatches[2].DepartureAt)
+		assert.Equal(t, "LATE", result.Dispatches[2].VehicleId)
+	})
+	t.Run("return error for a trip which is longer than every shift", func(t *testing.T) {
+		_, err := CalculateDeliveryTime(firstLineInput, packageDetails, [][]string{{"2", "70", "200"}}, scheduleOptions([]string{"08:00-09:00"}, nil))
+
+		assert.EqualError(t, err, "Validate schedule error: A trip of 3.56 hours of vehicle 1 is longer than its shifts")
+	})
+	t.Run("return error for shifts without a dispatch start", func(t *testing.T) {
+		options := SolverOptions{Offers: defaultOfferCatalog()}
+		assert.NoError(t, setSchedule(&options, []string{"08:00-12:00"}, nil))
+
+		_, err := CalculateDeliveryTime(firstLineInput, packageDetails, [][]string{{"2", "70", "200"}}, options)
+
+		assert.EqualError(t, err, "Validate schedule error: Shifts need a dispatch start")
+	})
+}
+
+func TestScheduleDeparture(t *testing.T) {
+	schedule := Schedule{
+		Start:  time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC),
+		Shifts: [][]shift{{{Start: 8 * 60, End: 12 * 60}, {Start: 13 * 60, End: 18 * 60}}},
+	}
+
+	t.Run("leave at once if the trip fits in the shift", func(t *testing.T) {
+		departure, ok := schedule.departure(0, 1.5, 2)
+
+		assert.True(t, ok)
+		assert.Equal(t, 1.5, departure)
+	})
+	t.Run("wait for the shift after the break", func(t *testing.T) {
+		departure, ok := schedule.departure(0, 3, 2)
+
+		assert.True(t, ok)
+		assert.Equal(t, 5.0, departure)
+	})
+	t.Run("leave without limits for a zero schedule", func(t *testing.T) {
+		departure, ok := Schedule{}.departure(0, 3, 20)
+
+		assert.True(t, ok)
+		assert.Equal(t, 3.0, departure)
+	})
+}
+
+func TestSetSchedule(t *testing.T) {
+	t.Run("return error for wrong shifts and days", func(t *testing.T) {
+		for _, test := range []struct {
+			shifts []string
+			days   []string
+			err    string
+		}{
+			{[]string{"8-12"}, nil, "schedule error: Wrong shift '8-12', use HH:MM-HH:MM windows in increasing order"},
+			{[]string{"12:00-08:00"}, nil, "schedule error: Wrong shift '12:00-08:00', use HH:MM-HH:MM windows in increasing order"},
+			{[]string{"08:00-12:00", "11:00-18:00"}, nil, "schedule error: Wrong shift '11:00-18:00', use HH:MM-HH:MM windows in increasing order"},
+			{nil, []string{"monday"}, "schedule error: Unknown working day 'monday', use mon, tue, wed, thu, fri, sat or sun"},
+		} {
+			options := SolverOptions{}
+
+			assert.EqualError(t, setSchedule(&options, test.shifts, test.days), test.err)
+		}
+	})
+	t.Run("accept a shift until the end of the day", func(t *testing.T) {
+		shifts, err := parseShifts([]string{"18:00-24:00"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []shift{{Start: 18 * 60, End: 24 * 60}}, shifts)
+	})
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// The body of the estimate endpoints, ExtraDetails is only used by the delivery time estimation
+type EstimateRequest struct {
+	FirstLineInput
+	Packages     []PackageDetail `json:"packages"`
+	ExtraDetails *ExtraDetails   `json:"extraDetails,omitempty"`
+	// Mark packages no vehicle can carry as undeliverable instead of rejecting the request
+	SkipUnshippable bool `json:"skipUnshippable,omitempty"`
+	// The day of the order (YYYY-MM-DD, default today) and the segment tags of the customer, used by the offer rules
+	OrderDate        string   `json:"orderDate,omitempty"`
+	CustomerSegments []string `json:"customerSegments,omitempty"`
+	// The name of the pricing model, default the default model of the server
+	PricingModel string `json:"pricingModel,omitempty"`
+	// The rounding of the amounts to cents (half-up, half-even or floor), default the one of the pricing model
+	Rounding string `json:"rounding,omitempty"`
+	// How the delivery times are rounded and shown, see the time flags of the command line
+	TimePrecision       *int   `json:"timePrecision,omitempty"`
+	TimeRounding        string `json:"timeRounding,omitempty"`
+	RoundAtPresentation bool   `json:"roundAtPresentation,omitempty"`
+	TimeUnit            string `json:"timeUnit,omitempty"`
+	DispatchStart       string `json:"dispatchStart,omitempty"`
+	// The daily shifts of the vehicles without own shifts and the days with shifts, see the schedule flags
+	Shifts      []string `json:"shifts,omitempty"`
+	WorkingDays []string `json:"workingDays,omitempty"`
+	// Ship the packages which would miss their due time on a later trip first
+	PrioritizeDeadlines bool `json:"prioritizeDeadlines,omitempty"`
+	// Time the trips along a route through the package locations
+	Routing bool `json:"routing,omitempty"`
+	// The packing objective of the shipments, default max-load
+	Objective string `json:"objective,omitempty"`
+	// Improve the greedy plan with simulated annealing, see the optimizer flags, the time limit is at most the one of the server
+	Optimize           bool   `json:"optimize,omitempty"`
+	OptimizeSeed       int64  `json:"optimizeSeed,omitempty"`
+	OptimizeTimeLimit  string `json:"optimizeTimeLimit,omitempty"`
+	OptimizeIterations int    `json:"optimizeIterations,omitempty"`
+}
+
+type EstimateResponse struct {
+	Problem string `json:"problem"`
+	Result
+	// The inputs which are used but look wrong, e.g. unknown offer codes
+	Warnings []InputIssue `json:"warnings,omitempty"`
+}
+
+// A problem of the API with the path which solves it and the format of its inputs
+type problemDescription struct {
+	Key    string      `json:"key"`
+	Title  string      `json:"title"`
+	Path   string      `json:"path"`
+	Schema InputSchema `json:"schema"`
+	// A request body with the API example of every field
+	Example json.RawMessage `json:"example"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+	// Every invalid input of the request, for a validation error
+	Issues []InputIssue `json:"issues,omitempty"`
+}
+
+// The max size of a request body
+const maxRequestBodySize = 1 << 20
+
+// Function to run the "serve" subcommand until the process is interrupted
+func runServe(args []string, stderr io.Writer) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	address := flags.String("addr", ":8080", "address to listen on")
+	offersPath := flags.String("offers", "", "path of the offer catalog file (.json, .yaml or .yml)")
+	pricingPath := flags.String("pricing", "", "path of the pricing models file (.json, .yaml or .yml)")
+	packingBudget := flags.Duration("packing-budget", time.Second, "time limit of the exact shipment packing of a request (0 means no limit)")
+	optimizeTime := flags.Duration("optimize-time", time.Second, "max time limit of the optimizer of a request (0 means no limit, a request stopped by it isn't reproducible)")
+	configPath := flags.String("config", "", "YAML or JSON file with the value of the flags by their name, the flags of the command line come first")
+	verbose := flags.Bool("verbose", false, "write the loaded files and the settings to stderr")
+	if status := parseCommandFlags(flags, configPath, false, args, stderr); status != 0 {
+		return maxStatus(status)
+	}
+
+	options, err := loadSolverOptions(*offersPath, *pricingPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	options.PackingTimeBudget = *packingBudget
+	options.Optimizer.TimeLimit = *optimizeTime
+
+	logger := log.New(stderr, "", log.LstdFlags)
+	if *verbose {
+		if *configPath != "" {
+			logger.Printf("config: %s", *configPath)
+		}
+		logger.Printf("offers: %d offers, %s catalog", len(options.Offers.Offers), sourceOf(*offersPath))
+		logger.Printf("pricing: %s model, %s catalog", options.PricingModel.Name, sourceOf(*pricingPath))
+	}
+	server := &http.Server{
+		Addr:              *address,
+		Handler:           newServeMux(options, logger),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Printf("listening on %s", *address)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		logger.Println(err)
+		return 1
+	case <-ctx.Done():
+	}
+
+	logger.Println("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Println(err)
+		return 1
+	}
+	return 0
+}
+
+// Function to create the routes of the HTTP API
+func newServeMux(options SolverOptions, logger *log.Logger) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	problems := []problemDescription{}
+	for _, problem := range listProblems() {
+		if problem.Estimate == nil {
+			continue
+		}
+		estimate := problem.Estimate
+		mux.HandleFunc("/v1/estimate/"+problem.Route, estimateHandler(logger, func(request EstimateRequest) (EstimateResponse, error) {
+			return estimate(request, options)
+		}))
+		problems = append(problems, problemDescription{
+			Key:     problem.Key,
+			Title:   problem.Title,
+			Path:    "/v1/estimate/" + problem.Route,
+			Schema:  problem.Schema,
+			Example: problem.Schema.apiExample(),
+		})
+	}
+	mux.HandleFunc("/v1/problems", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string][]problemDescription{"problems": problems})
+	})
+	return mux
+}
+
+// Function to wrap an estimator with the JSON decoding and error responses
+func estimateHandler(logger *log.Logger, estimator func(EstimateRequest) (EstimateResponse, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		var request EstimateRequest
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&request); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("decode request error: %s", err))
+			return
+		}
+
+		response, err := estimator(request)
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error(), Issues: validationErr.Issues})
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		logger.Printf("%s %s: %d packages", r.Method, r.URL.Path, len(request.Packages))
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// Function to solve the "Delivery Cost Estimation" problem of a request
+func estimateCost(request EstimateRequest, options SolverOptions) (EstimateResponse, error) {
+	packageDetails, warnings, err := validateEstimateRequest(request, options.Offers)
+	if err != nil {
+		return EstimateResponse{}, err
+	}
+	if err := setRequestOptions(&options, request); err != nil {
+		return EstimateResponse{}, err
+	}
+
+	return EstimateResponse{
+		Problem:  "Delivery Cost Estimation with Offers",
+		Result:   estimateDeliveryCost(request.FirstLineInput, packageDetails, options),
+		Warnings: warnings,
+	}, nil
+}
+
+// Function to solve the "Delivery Time Estimation" problem of a request
+func estimateTime(request EstimateRequest, options SolverOptions) (EstimateResponse, error) {
+	packageDetails, warnings, err := validateEstimateRequest(request, options.Offers)
+	if err != nil {
+		return EstimateResponse{}, err
+	}
+	if err := setRequestOptions(&options, request); err != nil {
+		return EstimateResponse{}, err
+	}
+
+	extraDetails := request.ExtraDetails
+	if extraDetails == nil {
+		return EstimateResponse{}, errors.New("Validate extra details error: Wrong number of inputs")
+	}
+	options.SkipUnshippable = request.SkipUnshippable
+	options.PrioritizeDeadlines = request.PrioritizeDeadlines
+	options.Routing = request.Routing
+	if err := setRequestOptimizer(&options, request); err != nil {
+		return EstimateResponse{}, err
+	}
+
+	result, err := estimateDeliveryTime(request.FirstLineInput, packageDetails, *extraDetails, options)
+	if err != nil {
+		return EstimateResponse{}, err
+	}
+	return EstimateResponse{Problem: "Delivery Time Estimation", Result: result, Warnings: warnings}, nil
+}
+
+// Function to apply the order details and pricing model of a request to the server options
+func setRequestOptions(options *SolverOptions, request EstimateRequest) error {
+	if err := setOrderOptions(options, request.OrderDate, request.CustomerSegments); err != nil {
+		return err
+	}
+	if request.PricingModel != "" {
+		if err := setPricingModel(options, request.PricingModel); err != nil {
+			return err
+		}
+	}
+	if err := setRounding(options, request.Rounding); err != nil {
+		return err
+	}
+	if request.Objective != "" {
+		if err := setPackingObjective(options, request.Objective); err != nil {
+			return err
+		}
+	}
+	if len(request.Shifts) > 0 || len(request.WorkingDays) > 0 {
+		if err := setSchedule(options, request.Shifts, request.WorkingDays); err != nil {
+			return err
+		}
+	}
+	return setRequestTimeModel(options, request)
+}
+
+// Function to apply the time model of a request, the server model is kept if the request sets none
+func setRequestTimeModel(options *SolverOptions, request EstimateRequest) error {
+	if request.TimePrecision == nil && request.TimeRounding == "" && !request.RoundAtPresentation && request.TimeUnit == "" && request.DispatchStart == "" {
+		return nil
+	}
+	precision := options.activeTimeModel().Precision
+	if request.TimePrecision != nil {
+		precision = *request.TimePrecision
+	}
+	return setTimeModel(options, precision, request.TimeRounding, request.RoundAtPresentation, request.TimeUnit, request.DispatchStart)
+}
+
+// Function to apply the optimizer settings of a request, its time limit is at most the one of the server
+func setRequestOptimizer(options *SolverOptions, request EstimateRequest) error {
+	serverLimit := options.Optimizer.TimeLimit
+	if err := setOptimizer(options, request.Optimize, request.OptimizeSeed, request.OptimizeTimeLimit, request.OptimizeIterations); err != nil {
+		return err
+	}
+	if serverLimit > 0 && (options.Optimizer.TimeLimit == 0 || options.Optimizer.TimeLimit > serverLimit) {
+		options.Optimizer.TimeLimit = serverLimit
+	}
+	return nil
+}
+
+// Function to check a request with the same rules as the console input and index its packages
+// Every issue of the request is returned together, the unknown offer codes are only warnings
+func validateEstimateRequest(request EstimateRequest, offers OfferCatalog) ([]PackageDetail, []InputIssue, error) {
+	issues := []InputIssue{}
+	if request.NumberOfPackages != len(request.Packages) {
+		issues = append(issues, InputIssue{Field: "numberOfPackages", Message: "parse first input line error: Wrong number of packages input"})
+	}
+	issues = append(issues, firstLineIssues(request.FirstLineInput)...)
+
+	checker := newPackageChecker(&offers)
+	packageDetails := make([]PackageDetail, 0, len(request.Packages))
+	for i, packageDetail := range request.Packages {
+		packageDetail.Index = i
+		packageIssues := validatePackageFields(packageDetail)
+		if len(packageIssues) == 0 {
+			packageIssues = checker.check(packageDetail, InputIssue{Package: i + 1}, 0)
+		}
+		for _, issue := range packageIssues {
+			issue.Package = i + 1
+			issues = append(issues, issue)
+		}
+		packageDetails = append(packageDetails, packageDetail)
+	}
+	if err := validationError(issues); err != nil {
+		return nil, nil, err
+	}
+	return packageDetails, checker.Warnings, nil
+}
+
+// Function to check a package which is decoded from JSON with the same rules as the console input and return every issue
+func validatePackageFields(packageDetail PackageDetail) []InputIssue {
+	issues := packageIssues(packageDetail)
+	add := func(field string, message string) {
+		issues = append(issues, InputIssue{Field: field, Message: "parse package inputs error: " + message})
+	}
+	for _, offerId := range packageDetail.OfferIds {
+		if offerId == "" {
+			add("offers", "Empty offer code")
+		}
+	}
+	if d := packageDetail.Dimensions; d != nil && (d.Length <= 0 || d.Width <= 0 || d.Height <= 0) {
+		add("dimensions", "Wrong package dimensions input")
+	}
+	if packageDetail.Priority != "" {
+		if _, err := parsePriority(string(packageDetail.Priority)); err != nil {
+			add("priority", fmt.Sprintf("Wrong package priority input, %s", err))
+		}
+	}
+	windows := []struct {
+		field string
+		value string
+	}{{"after", packageDetail.NotBefore}, {"due", packageDetail.DueBy}}
+	for _, window := range windows {
+		if err := checkPackageTime(window.value); window.value != "" && err != nil {
+			add(window.field, fmt.Sprintf("Wrong package window input, %s", err))
+		}
+	}
+	return issues
+}
+
+// Function to write a JSON response
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// Function to write a JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeMux(t *testing.T) {
+	mux := newServeMux(SolverOptions{Offers: defaultOfferCatalog()}, log.New(io.Discard, "", 0))
+
+	post := func(path string, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return recorder
+	}
+
+	t.Run("return ok for health check", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"status": "ok"}`, recorder.Body.String())
+	})
+	t.Run("describe the problems and their inputs", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/problems", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var response struct {
+			Problems []problemDescription `json:"problems"`
+		}
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, 2, len(response.Problems))
+		assert.Equal(t, "/v1/estimate/time", response.Problems[1].Path)
+		assert.Equal(t, "maxSpeed", response.Problems[1].Schema.ExtraLines[0].Fields[1].Name)
+		assert.Equal(t, FieldMeasure, response.Problems[0].Schema.Package.Fields[1].Type)
+		assert.Equal(t, "object", response.Problems[0].Schema.Package.Fields[4].APIType)
+		assert.Equal(t, "extraDetails", response.Problems[1].Schema.ExtraLines[0].APIObject)
+	})
+	t.Run("solve the example request of every problem", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/problems", nil))
+		var response struct {
+			Problems []problemDescription `json:"problems"`
+		}
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+
+		for _, problem := range response.Problems {
+			recorder := post(problem.Path, string(problem.Example))
+
+			assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		}
+
+		// The vehicles replace the fields of the shipment detail
+		var request map[string]json.RawMessage
+		assert.NoError(t, json.Unmarshal(response.Problems[1].Example, &request))
+		vehicles := response.Problems[1].Schema.ExtraLines[0].APIAlternatives[0]
+		request["extraDetails"] = json.RawMessage(`{"` + vehicles.Name + `": ` + string(vehicles.APIExample) + `}`)
+		body, err := json.Marshal(request)
+		assert.NoError(t, err)
+		recorder = post("/v1/estimate/time", string(body))
+
+		assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		assert.Contains(t, recorder.Body.String(), `"vehicleId":"VAN1"`)
+	})
+	t.Run("return the estimated costs", func(t *testing.T) {
+		recorder := post("/v1/estimate/cost", `{
+			"baseCost": 100,
+			"numberOfPackages": 2,
+			"packages": [
+				{"title": "PKG1", "weight": 5, "distance": 5, "offerIds": ["OFR001"]},
+				{"title": "PKG3", "weight": 10, "distance": 100, "offerIds": ["OFR003"]}
+			]
+		}`)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{
+			"problem": "Delivery Cost Estimation with Offers",
+			"costs": [
+				{"title": "PKG1", "discount": 0, "totalCost": 175, "appliedOfferIds": []},
+				{"title": "PKG3", "discount": 35, "totalCost": 665, "appliedOfferIds": ["OFR003"],
+					"appliedOffers": [{"id": "OFR003", "type": "percent", "discount": 35}]}
+			]
+		}`, recorder.Body.String())
+	})
+	t.Run("return the estimated delivery times", func(t *testing.T) {
+		recorder := post("/v1/estimate/time", `{
+			"baseCost": 100,
+			"numberOfPackages": 5,
+			"packages": [
+				{"title": "PKG1", "weight": 50, "distance": 30, "offerIds": ["OFR001"]},
+				{"title": "PKG2", "weight": 75, "distance": 125, "offerIds": ["OFR008"]},
+				{"title": "PKG3", "weight": 175, "distance": 100, "offerIds": ["OFR003"]},
+				{"title": "PKG4", "weight": 110, "distance": 60, "offerIds": ["OFR002"]},
+				{"title": "PKG5", "weight": 155, "distance": 95, "offerIds": ["NA"]}
+			],
+			"extraDetails": {"numberOfVehicles": 2, "maxSpeed": 70, "maxCarriableWeight": 200}
+		}`)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var response EstimateResponse
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, 5, len(response.Shipments))
+		assert.Equal(t, "PKG4", response.Shipments[3].Title)
+		assert.Equal(t, moneyOf(105), response.Shipments[3].Discount)
+		assert.Equal(t, []string{"OFR002"}, response.Shipments[3].AppliedOfferIds)
+		assert.Equal(t, 0.85, response.Shipments[3].DeliveryTime)
+		assert.Equal(t, 1, response.Shipments[3].Vehicle)
+		assert.Equal(t, 4.19, response.Shipments[4].DeliveryTime)
+	})
+	t.Run("return the vehicle of each package for a fleet", func(t *testing.T) {
+		recorder := post("/v1/estimate/time", `{
+			"baseCost": 100,
+			"numberOfPackages": 2,
+			"packages": [
+				{"title": "PKG1", "weight": 15, "distance": 30, "offerIds": ["NA"]},
+				{"title": "PKG2", "weight": 75, "distance": 125, "offerIds": ["NA"]}
+			],
+			"extraDetails": {"vehicles": [
+				{"id": "BIKE1", "maxSpeed": 30, "maxCarriableWeight": 20},
+				{"id": "VAN1", "maxSpeed": 70, "maxCarriableWeight": 200}
+			]}
+		}`)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var response EstimateResponse
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, "BIKE1", response.Shipments[0].VehicleId)
+		assert.Equal(t, "VAN1", response.Shipments[1].VehicleId)
+	})
+	t.Run("return bad request for a vehicle without speed", func(t *testing.T) {
+		recorder := post("/v1/estimate/time", `{"baseCost": 100, "numberOfPackages": 0, "packages": [],
+			"extraDetails": {"vehicles": [{"id": "VAN1", "maxCarriableWeight": 200}]}}`)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.JSONEq(t, `{"error": "Validate extra details error: Wrong max speed of vehicle VAN1"}`, recorder.Body.String())
+	})
+	t.Run("return bad request for packages no vehicle can carry", func(t *testing.T) {
+		recorder := post("/v1/estimate/time", `{"baseCost": 100, "numberOfPackages": 1,
+			"packages": [{"title": "PKG1", "weight": 250, "distance": 30, "offerIds": []}],
+			"extraDetails": {"numberOfVehicles": 2, "maxSpeed": 70, "maxCarriableWeight": 200}}`)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.JSONEq(t, `{"error": "Validate shipment error: 1 package(s) heavier than the max carriable weight 200: PKG1 (250)"}`, recorder.Body.String())
+	})
+	t.Run("return undeliverable packages when skipping them", func(t *testing.T) {
+		recorder := post("/v1/estimate/time", `{"baseCost": 100, "numberOfPackages": 1, "skipUnshippable": true,
+			"packages": [{"title": "PKG1", "weight": 250, "distance": 30, "offerIds": []}],
+			"extraDetails": {"numberOfVehicles": 2, "maxSpeed": 70, "maxCarriableWeight": 200}}`)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var response EstimateResponse
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.True(t, response.Shipments[0].Undeliverable)
+	})
+	t.Run("return bad request for a wrong order date", func(t *testing.T) {
+		recorder := post("/v1/estimate/cost", `{"baseCost": 100, "numberOfPackages": 0, "packages": [], "orderDate": "01/02/2024"}`)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.JSONEq(t, `{"error": "parse order date error: '01/02/2024' should be a date like 2006-01-02"}`, recorder.Body.String())
+	})
+	t.Run("return bad request for unknown pricing model", func(t *testing.T) {
+		recorder := post("/v1/estimate/cost", `{"baseCost": 100, "numberOfPackages": 0, "packages": [], "pricingModel": "mars"}`)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.JSONEq(t, `{"error": "pricing model error: Unknown pricing model mars"}`, recorder.Body.String())
+	})
+	t.Run("return decimal amounts rounded with the rounding of the request", func(t *testing.T) {
+		recorder := post("/v1/estimate/cost", `{"baseCost": 100, "numberOfPackages": 1, "rounding": "floor",
+			"packages": [{"title": "PKG1", "weight": 10.5, "distance": 100.3, "offerIds": ["OFR003"]}]}`)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{
+			"problem": "Delivery Cost Estimation with Offers",
+			"costs": [
+				{"title": "PKG1", "discount": 35.32, "totalCost": 671.18, "appliedOfferIds": ["OFR003"],
+					"appliedOffers": [{"id": "OFR003", "type": "percent", "discount": 35.32}]}
+			]
+		}`, recorder.Body.String())
+	})
+	t.Run("return the delivery times in the time unit of the request", func(t *testing.T) {
+		recorder := post("/v1/estimate/time", `{"baseCost": 100, "numberOfPackages": 1, "timeUnit": "minutes",
+			"packages": [{"title": "PKG1", "weight": 50, "distance": 30, "offerIds": []}],
+			"extraDetails": {"numberOfVehicles": 1, "maxSpeed": 70, "maxCarriableWeight": 200}}`)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var response EstimateResponse
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, TimeMinutes, response.TimeUnit)
+		assert.Equal(t, 25.2, response.Shipments[0].DeliveryTime)
+	})
+	t.Run("return bad request for a wrong time unit", func(t *testing.T) {
+		recorder := post("/v1/estimate/time", `{"baseCost": 100, "numberOfPackages": 0, "packages": [], "timeUnit": "clock",
+			"extraDetails": {"numberOfVehicles": 1, "maxSpeed": 70, "maxCarriableWeight": 200}}`)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.JSONEq(t, `{"error": "time model error: The clock time unit needs a dispatch start"}`, recorder.Body.String())
+	})
+	t.Run("return the ETAs of the shifts of the request", func(t *testing.T) {
+		recorder := post("/v1/estimate/time", `{"baseCost": 100, "numberOfPackages": 1,
+			"dispatchStart": "2024-03-15T11:50:00Z", "shifts": ["08:00-12:00"],
+			"packages": [{"title": "PKG1", "weight": 50, "distance": 30, "offerIds": []}],
+			"extraDetails": {"numberOfVehicles": 1, "maxSpeed": 70, "maxCarriableWeight": 200}}`)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var response EstimateResponse
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, "2024-03-16T08:25:12Z", response.Shipments[0].DeliveryAt)
+	})
+	t.Run("return the SLA report of the package windows", func(t *testing.T) {
+		recorder := post("/v1/estimate/time", `{"baseCost": 100, "numberOfPackages": 1, "prioritizeDeadlines": true,
+			"packages": [{"title": "PKG1", "weight": 50, "distance": 30, "offerIds": [], "dueBy": "0.25"}],
+			"extraDetails": {"numberOfVehicles": 1, "maxSpeed": 70, "maxCarriableWeight": 200}}`)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var response EstimateResponse
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, &SlaReport{Checked: 1, Late: []SlaPackage{{Title: "PKG1", Deviation: 0.17}}}, response.Sla)
+	})
+	t.Run("return bad request for a wrong package window", func(t *testing.T) {
+		recorder := post("/v1/estimate/time", `{"baseCost": 100, "numberOfPackages": 1,
+			"packages": [{"title": "PKG1", "weight": 50, "distance": 30, "offerIds": [], "dueBy": "noon"}],
+			"extraDetails": {"numberOfVehicles": 1, "maxSpeed": 70, "maxCarriableWeight": 200}}`)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.JSONEq(t, `{"error": "package 1: parse package inputs error: Wrong package window input, 'noon' should be hours like 4.5 or a time like 2006-01-02T15:04:05Z",
+			"issues": [{"package": 1, "field": "due", "message": "parse package inputs error: Wrong package window input, 'noon' should be hours like 4.5 or a time like 2006-01-02T15:04:05Z"}]}`, recorder.Body.String())
+	})
+	t.Run("return every invalid package of a request", func(t *testing.T) {
+		recorder := post("/v1/estimate/cost", `{"baseCost": 100, "numberOfPackages": 2,
+			"packages": [{"title": "PKG1", "weight": -5, "distance": -30, "offerIds": ["OFR001"]},
+				{"title": "PKG1", "weight": 5, "distance": 30, "offerIds": [""]}]}`)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		var response errorResponse
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, []InputIssue{
+			{Package: 1, Field: "weight", Message: "Validate package error: The weight of package PKG1 should be more than 0, not -5"},
+			{Package: 1, Field: "distance", Message: "Validate package error: The distance of package PKG1 should be more than 0, not -30"},
+			{Package: 2, Field: "offers", Message: "parse package inputs error: Empty offer code"},
+		}, response.Issues)
+	})
+	t.Run("return a duplicate package id and the unknown offer codes", func(t *testing.T) {
+		recorder := post("/v1/estimate/cost", `{"baseCost": 100, "numberOfPackages": 2,
+			"packages": [{"title": "PKG1", "weight": 5, "distance": 5, "offerIds": ["OFR008"]},
+				{"title": "PKG1", "weight": 5, "distance": 5, "offerIds": []}]}`)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "package 2: Validate package error: Duplicate package id PKG1, first used on package 1")
+
+		recorder = post("/v1/estimate/cost", `{"baseCost": 100, "numberOfPackages": 1,
+			"packages": [{"title": "PKG1", "weight": 5, "distance": 5, "offerIds": ["OFR008"]}]}`)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var response EstimateResponse
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, []InputIssue{{Package: 1, Field: "offers", Message: "Unknown offer code OFR008 of package PKG1, it gives no discount", Warning: true}}, response.Warnings)
+	})
+	t.Run("return the route of the routing mode", func(t *testing.T) {
+		recorder := post("/v1/estimate/time", `{"baseCost": 100, "numberOfPackages": 2, "routing": true,
+			"packages": [{"title": "PKG1", "weight": 50, "distance": 30, "offerIds": [], "location": {"x": 30, "y": 0}},
+				{"title": "PKG2", "weight": 50, "distance": 50, "offerIds": [], "location": {"x": 30, "y": 40}}],
+			"extraDetails": {"numberOfVehicles": 1, "maxSpeed": 70, "maxCarriableWeight": 200}}`)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var response EstimateResponse
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, 1.0, response.Shipments[1].DeliveryTime)
+		assert.Equal(t, 120.0, response.Dispatches[0].RouteLength)
+	})
+	t.Run("return the report of the optimizer", func(t *testing.T) {
+		recorder := post("/v1/estimate/time", `{"baseCost": 100, "numberOfPackages": 2, "optimize": true, "optimizeSeed": 2, "optimizeIterations": 100,
+			"packages": [{"title": "PKG1", "weight": 50, "distance": 30, "offerIds": []}, {"title": "PKG2", "weight": 175, "distance": 100, "offerIds": []}],
+			"extraDetails": {"numberOfVehicles": 1, "maxSpeed": 70, "maxCarriableWeight": 200}}`)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var response EstimateResponse
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, int64(2), response.Optimization.Seed)
+		assert.Equal(t, 100, response.Optimization.Iterations)
+		assert.Equal(t, 3.26, response.Optimization.BaselineMakespan)
+		assert.Equal(t, 2.26, response.Optimization.Makespan)
+	})
+	t.Run("return bad request for an unknown packing objective", func(t *testing.T) {
+		recorder := post("/v1/estimate/time", `{"baseCost": 100, "numberOfPackages": 0, "packages": [], "objective": "cheapest",
+			"extraDetails": {"numberOfVehicles": 1, "maxSpeed": 70, "maxCarriableWeight": 200}}`)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.JSONEq(t, `{"error": "packing objective error: 'cheapest' is not a known objective, use one of makespan, max-load, min-avg-time, min-trips, revenue"}`, recorder.Body.String())
+	})
+	t.Run("return bad request for a wrong package priority", func(t *testing.T) {
+		recorder := post("/v1/estimate/cost", `{"baseCost": 100, "numberOfPackages": 1,
+			"packages": [{"title": "PKG1", "weight": 50, "distance": 30, "offerIds": [], "priority": "urgent"}]}`)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.JSONEq(t, `{"error": "package 1: parse package inputs error: Wrong package priority input, Unknown priority 'urgent', use standard, express or same-day",
+			"issues": [{"package": 1, "field": "priority", "message": "parse package inputs error: Wrong package priority input, Unknown priority 'urgent', use standard, express or same-day"}]}`, recorder.Body.String())
+	})
+	t.Run("return bad request for missing extra details", func(t *testing.T) {
+		recorder := post("/v1/estimate/time", `{"baseCost": 100, "numberOfPackages": 0, "packages": []}`)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.JSONEq(t, `{"error": "Validate extra details error: Wrong number of inputs"}`, recorder.Body.String())
+	})
+	t.Run("return bad request for wrong number of packages", func(t *testing.T) {
+		recorder := post("/v1/estimate/cost", `{"baseCost": 100, "numberOfPackages": 2, "packages": []}`)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.JSONEq(t, `{"error": "parse first input line error: Wrong number of packages input",
+			"issues": [{"field": "numberOfPackages", "message": "parse first input line error: Wrong number of packages input"}]}`, recorder.Body.String())
+	})
+	t.Run("return bad request for unknown fields", func(t *testing.T) {
+		recorder := post("/v1/estimate/cost", `{"baseCost": 100, "packageCount": 2}`)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+	t.Run("return method not allowed for GET estimate", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/estimate/cost", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	})
+}
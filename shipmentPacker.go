@@ -0,0 +1,290 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// The max number of 64 bit words the packing table may use (32MB), bigger problems use the greedy packer
+const maxPackingTableWords = 1 << 22
+
+// Function to pick the packages of the next shipment
+// The given packages array should be sorted based on the weight
+//
+// The best subset has the max number of packages, then the max total weight, then the min max-distance.
+// Among equal subsets the one with the lowest indices wins, which is the first one the old full enumeration found.
+// It runs a dynamic programming over (number of packages, total weight) so the cost grows with
+// packages * subset size * max carriable weight instead of the number of combinations.
+// The weights are packed in milli units, so decimal weights are exact, and scaled down by their gcd.
+// The deadline is checked while the tables are built, after it the shipment is packed greedily (a zero deadline never ends).
+func packShipment(sortedPackages []PackageDetail, maxCarriableWeight int, deadline time.Time) Subset {
+	maxWeight := maxCarriableWeight * measureScale
+	subsetSize := findMaxSubsetSize(sortedPackages, maxCarriableWeight)
+	if subsetSize == 0 {
+		return Subset{}
+	}
+
+	// Only one package fits: the heaviest one, then the nearest one
+	if subsetSize == 1 {
+		best := -1
+		for i, p := range sortedPackages {
+			if milliUnits(p.Weight) <= maxWeight && (best == -1 || p.Weight > sortedPackages[best].Weight ||
+				(p.Weight == sortedPackages[best].Weight && p.Distance < sortedPackages[best].Distance)) {
+				best = i
+			}
+		}
+		return addToSubset(Subset{}, sortedPackages, best)
+	}
+
+	// A package can only be in the subset if it fits together with the lightest other ones,
+	// as the packages are sorted by weight these candidates are a prefix of the array
+	lightestSum := 0
+	for i := 0; i < subsetSize-1; i++ {
+		lightestSum += milliUnits(sortedPackages[i].Weight)
+	}
+	candidates := subsetSize
+	for candidates < len(sortedPackages) && milliUnits(sortedPackages[candidates].Weight)+lightestSum <= maxWeight {
+		candidates++
+	}
+	sortedPackages = sortedPackages[:candidates]
+
+	scale := weightScale(sortedPackages, maxWeight)
+	capacity := maxWeight / scale
+	weights := make([]int, len(sortedPackages))
+	for i, p := range sortedPackages {
+		weights[i] = milliUnits(p.Weight) / scale
+	}
+
+	words := (capacity+1)/64 + 1
+	if (len(sortedPackages)+1)*(subsetSize+1)*words > maxPackingTableWords {
+		return greedyShipment(sortedPackages, maxCarriableWeight, subsetSize)
+	}
+
+	// Max total weight of a subset with the max number of packages
+	fits := func(i int) bool { return milliUnits(sortedPackages[i].Weight) <= maxWeight }
+	reached, ok := reachableWeights(weights, subsetSize, capacity, fits, nil, deadline)
+	if !ok {
+		return greedyShipment(sortedPackages, maxCarriableWeight, subsetSize)
+	}
+	bestWeight := reached.highest()
+
+	// Min max-distance: add packages from the nearest one until the best weight is reachable
+	byDistance := make([]int, len(sortedPackages))
+	for i := range byDistance {
+		byDistance[i] = i
+	}
+	sort.Slice(byDistance, func(i, j int) bool {
+		a, b := sortedPackages[byDistance[i]], sortedPackages[byDistance[j]]
+		return a.Distance < b.Distance || (a.Distance == b.Distance && byDistance[i] < byDistance[j])
+	})
+	maxDistance := 0.0
+	if _, ok := reachableWeights(weights, subsetSize, capacity, fits, func(i int, reached bitset) bool {
+		maxDistance = sortedPackages[i].Distance
+		return reached.has(bestWeight)
+	}, deadline, byDistance...); !ok {
+		return greedyShipment(sortedPackages, maxCarriableWeight, subsetSize)
+	}
+
+	// Pick the packages with the lowest indices which still reach the best weight
+	eligible := func(i int) bool { return fits(i) && sortedPackages[i].Distance <= maxDistance }
+	table, ok := suffixTable(weights, subsetSize, capacity, eligible, deadline)
+	if !ok {
+		return greedyShipment(sortedPackages, maxCarriableWeight, subsetSize)
+	}
+
+	subset := Subset{}
+	remainedSize, remainedWeight := subsetSize, bestWeight
+	for i := 0; i < len(sortedPackages) && remainedSize > 0; i++ {
+		if !eligible(i) || weights[i] > remainedWeight || !table[i+1][remainedSize-1].has(remainedWeight-weights[i]) {
+			continue
+		}
+		subset = addToSubset(subset, sortedPackages, i)
+		remainedSize--
+		remainedWeight -= weights[i]
+	}
+	return subset
+}
+
+// Function to find the reachable total weights of subsets with the given size
+// The packages are added in the given order (or index order), after each one stop is called and can end the search
+// It returns false when the deadline passes before the search ends
+func reachableWeights(weights []int, subsetSize int, capacity int, eligible func(int) bool, stop func(int, bitset) bool, deadline time.Time, order ...int) (bitset, bool) {
+	if len(order) == 0 {
+		order = make([]int, len(weights))
+		for i := range order {
+			order[i] = i
+		}
+	}
+
+	reached := make([]bitset, subsetSize+1)
+	for c := range reached {
+		reached[c] = newBitset(capacity + 1)
+	}
+	reached[0].set(0)
+
+	for added, i := range order {
+		if isPastDeadline(deadline) {
+			return nil, false
+		}
+		if eligible(i) {
+			for c := minInt(subsetSize, added+1); c > 0; c-- {
+				reached[c].orShifted(reached[c-1], weights[i], capacity+1)
+			}
+		}
+		if stop != nil && stop(i, reached[subsetSize]) {
+			break
+		}
+	}
+	return reached[subsetSize], true
+}
+
+// Function to build table[i][c]: the total weights reachable with c eligible packages from index i to the end
+// It returns false when the deadline passes before the table is built
+func suffixTable(weights []int, subsetSize int, capacity int, eligible func(int) bool, deadline time.Time) ([][]bitset, bool) {
+	words := len(newBitset(capacity + 1))
+	block := make([]uint64, (len(weights)+1)*(subsetSize+1)*words)
+
+	table := make([][]bitset, len(weights)+1)
+	for i := len(weights); i >= 0; i-- {
+		if isPastDeadline(deadline) {
+			return nil, false
+		}
+		table[i] = make([]bitset, subsetSize+1)
+		for c := range table[i] {
+			offset := (i*(subsetSize+1) + c) * words
+			table[i][c] = bitset(block[offset : offset+words : offset+words])
+		}
+		if i == len(weights) {
+			table[i][0].set(0)
+			continue
+		}
+		for c := range table[i] {
+			copy(table[i][c], table[i+1][c])
+		}
+		if eligible(i) {
+			for c := 1; c <= subsetSize; c++ {
+				table[i][c].orShifted(table[i+1][c-1], weights[i], capacity+1)
+			}
+		}
+	}
+	return table, true
+}
+
+// Function to pick the heaviest packages which still leave room for a subset with the given size
+// It's used when the exact packing is too big or out of time, the distance is not considered
+func greedyShipment(sortedPackages []PackageDetail, maxCarriableWeight int, subsetSize int) Subset {
+	maxWeight := maxCarriableWeight * measureScale
+	lightestSums := make([]int, len(sortedPackages)+1)
+	for i, p := range sortedPackages {
+		lightestSums[i+1] = lightestSums[i] + milliUnits(p.Weight)
+	}
+
+	totalWeight := 0
+	indices := []int{}
+	for i := len(sortedPackages) - 1; i >= 0 && len(indices) < subsetSize; i-- {
+		// The lightest packages which are needed after this one, all of them have lower indices than the chosen ones
+		needed := subsetSize - len(indices) - 1
+		lightest := lightestSums[needed]
+		if i < needed {
+			lightest = lightestSums[needed+1] - milliUnits(sortedPackages[i].Weight)
+		}
+		if weight := milliUnits(sortedPackages[i].Weight); totalWeight+weight+lightest <= maxWeight {
+			indices = append(indices, i)
+			totalWeight += weight
+		}
+	}
+
+	subset := Subset{}
+	for j := len(indices) - 1; j >= 0; j-- {
+		subset = addToSubset(subset, sortedPackages, indices[j])
+	}
+	return subset
+}
+
+// Function to add a package to a subset
+func addToSubset(subset Subset, packages []PackageDetail, index int) Subset {
+	subset.PackageDetailIndices = append(subset.PackageDetailIndices, index)
+	subset.PackageDetails = append(subset.PackageDetails, packages[index])
+	subset.TotalWeight += packages[index].Weight
+	if subset.MaxDistance < packages[index].Distance {
+		subset.MaxDistance = packages[index].Distance
+	}
+	return subset
+}
+
+// Function to find the greatest common divisor of the package weights in milli units, so the packing table can be smaller
+func weightScale(packages []PackageDetail, maxWeight int) int {
+	scale := 0
+	for _, p := range packages {
+		if weight := milliUnits(p.Weight); weight <= maxWeight {
+			scale = gcd(scale, weight)
+		}
+	}
+	if scale == 0 {
+		return 1
+	}
+	return scale
+}
+
+func gcd(a int, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Function to check if the packing time budget is over, a zero deadline never ends
+func isPastDeadline(deadline time.Time) bool {
+	return !deadline.IsZero() && time.Now().After(deadline)
+}
+
+// A fixed size set of small non-negative integers
+type bitset []uint64
+
+func newBitset(size int) bitset {
+	return make(bitset, size/64+1)
+}
+
+func (b bitset) has(i int) bool {
+	return i >= 0 && b[i/64]&(1<<(uint(i)%64)) != 0
+}
+
+func (b bitset) set(i int) {
+	b[i/64] |= 1 << (uint(i) % 64)
+}
+
+// Function to return the highest member of the set, or -1 for an empty set
+func (b bitset) highest() int {
+	for word := len(b) - 1; word >= 0; word-- {
+		for bit := 63; bit >= 0; bit-- {
+			if b[word]&(1<<uint(bit)) != 0 {
+				return word*64 + bit
+			}
+		}
+	}
+	return -1
+}
+
+// Function to add every member of src plus shift, members from size on are dropped
+func (b bitset) orShifted(src bitset, shift int, size int) {
+	wordShift, bitShift := shift/64, uint(shift%64)
+	for word := len(b) - 1; word >= wordShift; word-- {
+		value := src[word-wordShift] << bitShift
+		if bitShift != 0 && word-wordShift-1 >= 0 {
+			value |= src[word-wordShift-1] >> (64 - bitShift)
+		}
+		b[word] |= value
+	}
+	if extra := uint(size % 64); extra != 0 {
+		b[len(b)-1] &= (1 << extra) - 1
+	} else if len(b)*64 > size {
+		b[len(b)-1] = 0
+	}
+}
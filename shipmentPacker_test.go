@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The full enumeration the packer replaced, used as the reference of the tie-break rules
+func enumerateBestSubset(sortedPackages []PackageDetail, maxCarriableWeight int) Subset {
+	subsetSize := findMaxSubsetSize(sortedPackages, maxCarriableWeight)
+	best := Subset{}
+	found := false
+
+	var enumerate func(index int, current Subset)
+	enumerate = func(index int, current Subset) {
+		if len(current.PackageDetailIndices) == subsetSize {
+			currentWeight, bestWeight := milliUnits(current.TotalWeight), milliUnits(best.TotalWeight)
+			if !found || currentWeight > bestWeight || (currentWeight == bestWeight && current.MaxDistance < best.MaxDistance) {
+				best = current
+				found = true
+			}
+			return
+		}
+		if index == len(sortedPackages) {
+			return
+		}
+		if milliUnits(current.TotalWeight+sortedPackages[index].Weight) <= maxCarriableWeight*measureScale {
+			with := current
+			with.PackageDetailIndices = append(append([]int{}, current.PackageDetailIndices...), index)
+			with.PackageDetails = append(append([]PackageDetail{}, current.PackageDetails...), sortedPackages[index])
+			with.TotalWeight += sortedPackages[index].Weight
+			if with.MaxDistance < sortedPackages[index].Distance {
+				with.MaxDistance = sortedPackages[index].Distance
+			}
+			enumerate(index+1, with)
+		}
+		enumerate(index+1, current)
+	}
+	enumerate(0, Subset{})
+	return best
+}
+
+// Function to make packages with random weights and distances, the weights have the given number of decimals
+func randomPackages(random *rand.Rand, count int, maxWeight int, maxDistance int, decimals int) []PackageDetail {
+	weightScale := math.Pow10(decimals)
+	packages := make([]PackageDetail, count)
+	for i := range packages {
+		packages[i] = PackageDetail{
+			Index:    i,
+			Title:    fmt.Sprintf("PKG%d", i+1),
+			Weight:   float64(random.Intn(maxWeight*int(weightScale))+1) / weightScale,
+			Distance: float64(random.Intn(maxDistance) + 1),
+		}
+	}
+	sort.SliceStable(packages, func(i, j int) bool {
+		return packages[i].Weight < packages[j].Weight
+	})
+	return packages
+}
+
+func TestPackShipment(t *testing.T) {
+	t.Run("return the same subset as the full enumeration", func(t *testing.T) {
+		random := rand.New(rand.NewSource(1))
+		for round := 0; round < 300; round++ {
+			packages := randomPackages(random, random.Intn(12)+1, 60, 20, 0)
+			maxCarriableWeight := random.Intn(150) + 1
+
+			expected := enumerateBestSubset(packages, maxCarriableWeight)
+			actual := packShipment(packages, maxCarriableWeight, time.Time{})
+
+			assert.Equal(t, expected, actual, "round %d", round)
+		}
+	})
+	t.Run("return the same subset as the full enumeration for decimal weights", func(t *testing.T) {
+		random := rand.New(rand.NewSource(5))
+		for round := 0; round < 100; round++ {
+			packages := randomPackages(random, random.Intn(10)+1, 30, 20, 1)
+			maxCarriableWeight := random.Intn(60) + 1
+
+			expected := enumerateBestSubset(packages, maxCarriableWeight)
+			actual := packShipment(packages, maxCarriableWeight, time.Time{})
+
+			assert.Equal(t, expected.PackageDetailIndices, actual.PackageDetailIndices, "round %d", round)
+		}
+	})
+	t.Run("fit decimal weights which add up to the max carriable weight", func(t *testing.T) {
+		packages := []PackageDetail{
+			{Index: 0, Title: "PKG1", Weight: 0.1, Distance: 10},
+			{Index: 1, Title: "PKG2", Weight: 0.2, Distance: 10},
+			{Index: 2, Title: "PKG3", Weight: 2.7, Distance: 10},
+		}
+		subset := packShipment(packages, 3, time.Time{})
+
+		assert.Equal(t, []int{0, 1, 2}, subset.PackageDetailIndices)
+	})
+	t.Run("return the subset of the problem statement", func(t *testing.T) {
+		packages := []PackageDetail{
+			{Index: 0, Title: "PKG1", Weight: 50, Distance: 30},
+			{Index: 1, Title: "PKG2", Weight: 75, Distance: 125},
+			{Index: 3, Title: "PKG4", Weight: 110, Distance: 60},
+			{Index: 4, Title: "PKG5", Weight: 155, Distance: 95},
+			{Index: 2, Title: "PKG3", Weight: 175, Distance: 100},
+		}
+		subset := packShipment(packages, 200, time.Time{})
+
+		assert.Equal(t, []int{1, 2}, subset.PackageDetailIndices)
+		assert.Equal(t, 185.0, subset.TotalWeight)
+		assert.Equal(t, 125.0, subset.MaxDistance)
+	})
+	t.Run("return empty subset if nothing fits", func(t *testing.T) {
+		subset := packShipment([]PackageDetail{{Title: "PKG1", Weight: 300}}, 200, time.Time{})
+
+		assert.Equal(t, Subset{}, subset)
+	})
+	t.Run("pack a big shipment greedily when the deadline passes while packing", func(t *testing.T) {
+		// The exact packing of this shipment fits in the packing table and takes milliseconds
+		packages := randomPackages(rand.New(rand.NewSource(5)), 1000, 20, 250, 1)
+		subset := packShipment(packages, 200, time.Now().Add(time.Microsecond))
+
+		assert.Equal(t, greedyShipment(packages, 200, findMaxSubsetSize(packages, 200)), subset)
+		assert.NotEqual(t, packShipment(packages, 200, time.Time{}), subset)
+	})
+}
+
+func TestGreedyShipment(t *testing.T) {
+	t.Run("return a subset with the max number of packages", func(t *testing.T) {
+		random := rand.New(rand.NewSource(2))
+		for round := 0; round < 100; round++ {
+			packages := randomPackages(random, random.Intn(30)+1, 60, 20, 0)
+			maxCarriableWeight := random.Intn(150) + 1
+			subsetSize := findMaxSubsetSize(packages, maxCarriableWeight)
+
+			subset := greedyShipment(packages, maxCarriableWeight, subsetSize)
+
+			assert.Equal(t, subsetSize, len(subset.PackageDetails), "round %d", round)
+			assert.LessOrEqual(t, subset.TotalWeight, float64(maxCarriableWeight), "round %d", round)
+		}
+	})
+}
+
+func TestGetShipmentSubsets(t *testing.T) {
+	t.Run("ship every package once after the time budget is over", func(t *testing.T) {
+		packages := randomPackages(rand.New(rand.NewSource(3)), 200, 100, 200, 0)
+		subsets, err := getShipmentSubsets(packages, []Vehicle{{Id: "1", MaxSpeed: 70, MaxCarriableWeight: 200}}, planSettings{Deadline: time.Now().Add(-time.Second), TimeModel: defaultTimeModel()})
+
+		assert.NoError(t, err)
+
+		shipped := map[int]bool{}
+		for _, subset := range subsets {
+			assert.LessOrEqual(t, subset.TotalWeight, 200.0)
+			for _, p := range subset.PackageDetails {
+				assert.False(t, shipped[p.Index])
+				shipped[p.Index] = true
+			}
+		}
+		assert.Equal(t, 200, len(shipped))
+	})
+}
+
+func TestBitset(t *testing.T) {
+	t.Run("shift members and drop the ones out of size", func(t *testing.T) {
+		src := newBitset(130)
+		src.set(0)
+		src.set(63)
+		src.set(100)
+		dst := newBitset(130)
+		dst.orShifted(src, 65, 130)
+
+		assert.True(t, dst.has(65))
+		assert.True(t, dst.has(128))
+		assert.False(t, dst.has(64))
+		assert.Equal(t, 128, dst.highest())
+	})
+}
+
+func benchmarkGetShipmentSubsets(b *testing.B, count int) {
+	packages := randomPackages(rand.New(rand.NewSource(4)), count, 200, 250, 0)
+	fleet := ExtraDetails{NumberOfVehicles: 2, MaxSpeed: 70, MaxCarriableWeight: 200}.fleet()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		getShipmentSubsets(packages, fleet, planSettings{TimeModel: defaultTimeModel()})
+	}
+}
+
+func BenchmarkGetShipmentSubsets100(b *testing.B)  { benchmarkGetShipmentSubsets(b, 100) }
+func BenchmarkGetShipmentSubsets1000(b *testing.B) { benchmarkGetShipmentSubsets(b, 1000) }
+func BenchmarkGetShipmentSubsets5000(b *testing.B) { benchmarkGetShipmentSubsets(b, 5000) }
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// How the delivery times are rounded to the precision
+type TimeRounding string
+
+const (
+	TimeTruncate TimeRounding = "truncate" // Cut the decimals after the precision, the rounding of the problem statement
+	TimeRound    TimeRounding = "round"    // Round to the nearest value, halves up
+	TimeCeil     TimeRounding = "ceil"     // Round up
+)
+
+// The unit of the delivery, departure and return times in the output
+type TimeUnit string
+
+const (
+	TimeHours   TimeUnit = "hours"   // Hours since the dispatch start, as in the problem statement
+	TimeMinutes TimeUnit = "minutes" // Minutes since the dispatch start
+	TimeClock   TimeUnit = "clock"   // Wall-clock timestamps (RFC 3339) from the dispatch start, the hours are kept too
+)
+
+// How the times of the delivery time estimation are rounded and shown
+// A zero TimeModel is the model of the problem statement
+type TimeModel struct {
+	Precision int          // The number of decimals of the times
+	Rounding  TimeRounding // How the times are cut to the precision
+	// Round only the times in the output, the trips are planned with the exact times.
+	// Otherwise, as in the problem statement, each travel time is rounded before it's added up.
+	RoundAtPresentation bool
+	Unit                TimeUnit
+	DispatchStart       time.Time // The wall-clock time of hour 0, needed for the clock unit
+}
+
+// The time model of the problem statement: 2 decimals of hours, truncated at each step
+func defaultTimeModel() TimeModel {
+	return TimeModel{Precision: 2, Rounding: TimeTruncate, Unit: TimeHours}
+}
+
+// Function to return the time model of the solvers, the model of the problem statement if none is set
+func (options SolverOptions) activeTimeModel() TimeModel {
+	if options.TimeModel.Rounding == "" {
+		return defaultTimeModel()
+	}
+	return options.TimeModel
+}
+
+// Function to set the time model of the solvers, empty values keep the default of the problem statement
+// The dispatch start is a RFC 3339 timestamp like 2024-03-15T08:00:00Z
+func setTimeModel(options *SolverOptions, precision int, rounding string, roundAtPresentation bool, unit string, dispatchStart string) error {
+	model := defaultTimeModel()
+	if precision < 0 || precision > 6 {
+		return fmt.Errorf("time model error: Time precision should be between 0 and 6")
+	}
+	model.Precision = precision
+	model.RoundAtPresentation = roundAtPresentation
+
+	switch TimeRounding(rounding) {
+	case "":
+	case TimeTruncate, TimeRound, TimeCeil:
+		model.Rounding = TimeRounding(rounding)
+	default:
+		return fmt.Errorf("time model error: Unknown time rounding \"%s\", use %s, %s or %s", rounding, TimeTruncate, TimeRound, TimeCeil)
+	}
+
+	switch TimeUnit(unit) {
+	case "":
+	case TimeHours, TimeMinutes, TimeClock:
+		model.Unit = TimeUnit(unit)
+	default:
+		return fmt.Errorf("time model error: Unknown time unit \"%s\", use %s, %s or %s", unit, TimeHours, TimeMinutes, TimeClock)
+	}
+
+	if dispatchStart != "" {
+		start, err := time.Parse(time.RFC3339, dispatchStart)
+		if err != nil {
+			return fmt.Errorf("time model error: Dispatch start '%s' should be a time like 2006-01-02T15:04:05Z", dispatchStart)
+		}
+		model.DispatchStart = start
+	}
+	if model.Unit == TimeClock && model.DispatchStart.IsZero() {
+		return fmt.Errorf("time model error: The clock time unit needs a dispatch start")
+	}
+
+	options.TimeModel = model
+	return nil
+}
+
+// Function to round a time to the precision with the rounding of the model
+func (model TimeModel) round(value float64) float64 {
+	scale := math.Pow10(model.Precision)
+	// Drop the float error first, so 3.98 stored as 3.97999... isn't truncated to 3.97
+	scaled := math.Round(value*scale*1e6) / 1e6
+	switch model.Rounding {
+	case TimeRound:
+		scaled = math.Round(scaled)
+	case TimeCeil:
+		scaled = math.Ceil(scaled)
+	default:
+		scaled = math.Floor(scaled)
+	}
+	return scaled / scale
+}
+
+// Function to round a travel time while planning, it's kept exact if the model rounds only the output
+func (model TimeModel) step(hours float64) float64 {
+	if model.RoundAtPresentation {
+		return hours
+	}
+	return model.round(hours)
+}
+
+// Function to convert a time in hours to the output unit and round it
+func (model TimeModel) present(hours float64) float64 {
+	if model.Unit == TimeMinutes {
+		return model.round(hours * 60)
+	}
+	return model.round(hours)
+}
+
+// Function to return the wall-clock timestamp of a time in hours, empty if the unit isn't clock
+// The time isn't rounded to the precision again, a trip postponed to a shift can start at any second
+func (model TimeModel) clock(hours float64) string {
+	if model.Unit != TimeClock {
+		return ""
+	}
+	return model.DispatchStart.Add(hoursDuration(hours)).Format(time.RFC3339)
+}
+
+// Function to write a time of the output, the wall-clock timestamp if there is one
+func (model TimeModel) format(value float64, clock string) string {
+	if clock != "" {
+		return clock
+	}
+	precision := model.Precision
+	if model.Rounding == "" {
+		precision = defaultTimeModel().Precision
+	}
+	return strconv.FormatFloat(value, 'f', precision, 64)
+}
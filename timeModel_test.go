@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeModelRound(t *testing.T) {
+	t.Run("round with each strategy", func(t *testing.T) {
+		for _, test := range []struct {
+			rounding TimeRounding
+			expected float64
+		}{
+			{TimeTruncate, 1.78},
+			{TimeRound, 1.79},
+			{TimeCeil, 1.79},
+		} {
+			model := TimeModel{Precision: 2, Rounding: test.rounding}
+
+			assert.Equal(t, test.expected, model.round(125.0/70), test.rounding)
+		}
+	})
+	t.Run("don't cut values which only have a float error", func(t *testing.T) {
+		assert.Equal(t, 3.98, defaultTimeModel().round(3.56+0.42))
+		assert.Equal(t, 0.3, TimeModel{Precision: 2, Rounding: TimeCeil}.round(0.1+0.2))
+	})
+	t.Run("use the selected precision", func(t *testing.T) {
+		assert.Equal(t, 1.785, TimeModel{Precision: 3, Rounding: TimeTruncate}.round(125.0/70))
+		assert.Equal(t, 2.0, TimeModel{Precision: 0, Rounding: TimeRound}.round(125.0/70))
+	})
+}
+
+func TestTimeModelPresent(t *testing.T) {
+	t.Run("return the time in minutes", func(t *testing.T) {
+		model := defaultTimeModel()
+		model.Unit = TimeMinutes
+
+		assert.Equal(t, 238.8, model.present(3.98))
+	})
+	t.Run("return the wall-clock time only for the clock unit", func(t *testing.T) {
+		options := SolverOptions{}
+		assert.NoError(t, setTimeModel(&options, 2, "", false, "clock", "2024-03-15T08:00:00+01:00"))
+
+		assert.Equal(t, "2024-03-15T11:58:48+01:00", options.TimeModel.clock(3.98))
+		assert.Equal(t, "", defaultTimeModel().clock(3.98))
+	})
+	t.Run("write the times with the precision", func(t *testing.T) {
+		assert.Equal(t, "4.40", TimeModel{}.format(4.4, ""))
+		assert.Equal(t, "4.400", TimeModel{Precision: 3, Rounding: TimeRound}.format(4.4, ""))
+		assert.Equal(t, "2024-03-15T08:51:00Z", TimeModel{}.format(0.85, "2024-03-15T08:51:00Z"))
+	})
+}
+
+func TestSetTimeModel(t *testing.T) {
+	t.Run("keep the model of the problem statement for empty values", func(t *testing.T) {
+		options := SolverOptions{}
+
+		assert.NoError(t, setTimeModel(&options, 2, "", false, "", ""))
+		assert.Equal(t, defaultTimeModel(), options.TimeModel)
+	})
+	t.Run("return error for invalid values", func(t *testing.T) {
+		for _, test := range []struct {
+			precision int
+			rounding  string
+			unit      string
+			start     string
+			err       string
+		}{
+			{-1, "", "", "", "time model error: Time precision should be between 0 and 6"},
+			{2, "floor", "", "", "time model error: Unknown time rounding \"floor\", use truncate, round or ceil"},
+			{2, "", "days", "", "time model error: Unknown time unit \"days\", use hours, minutes or clock"},
+			{2, "", "", "08:00", "time model error: Dispatch start '08:00' should be a time like 2006-01-02T15:04:05Z"},
+			{2, "", "clock", "", "time model error: The clock time unit needs a dispatch start"},
+		} {
+			options := SolverOptions{}
+
+			assert.EqualError(t, setTimeModel(&options, test.precision, test.rounding, false, test.unit, test.start), test.err)
+		}
+	})
+}